@@ -3,8 +3,12 @@ package main
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/conductorone/baton-sdk/pkg/cli"
+	"github.com/conductorone/baton-twingate/pkg/connector"
+	"github.com/conductorone/baton-twingate/pkg/connector/client"
 	"github.com/spf13/cobra"
 )
 
@@ -12,23 +16,190 @@ import (
 type config struct {
 	cli.BaseConfig `mapstructure:",squash"` // Puts the base config options in the same place as the connector options
 
-	ApiKey string `mapstructure:"api-key"`
-	Domain string `mapstructure:"domain"`
+	ApiKey               string   `mapstructure:"api-key"`
+	APIKeyFile           string   `mapstructure:"api-key-file"`
+	Domain               string   `mapstructure:"domain"`
+	APIURL               string   `mapstructure:"api-url"`
+	PrefetchGroupMembers bool     `mapstructure:"prefetch-group-members"`
+	IncrementalSync      bool     `mapstructure:"incremental-sync"`
+	RecordDir            string   `mapstructure:"record-dir"`
+	ReplayDir            string   `mapstructure:"replay-dir"`
+	PageSizes            []string `mapstructure:"page-size"`
+	GroupInclude         string   `mapstructure:"group-include"`
+	GroupExclude         string   `mapstructure:"group-exclude"`
+	GroupIDs             []string `mapstructure:"group-ids"`
+	ExcludeGroupIDs      []string `mapstructure:"exclude-group-ids"`
+	GroupTypes           []string `mapstructure:"group-types"`
+	ResourceTypes        []string `mapstructure:"resource-types"`
+	IdentityProvider     string   `mapstructure:"identity-provider"`
+	ProxyURL             string   `mapstructure:"proxy-url"`
+	ProxyUsername        string   `mapstructure:"proxy-username"`
+	ProxyPassword        string   `mapstructure:"proxy-password"`
+	CAFile               string   `mapstructure:"ca-file"`
+	ClientCert           string   `mapstructure:"client-cert"`
+	ClientKey            string   `mapstructure:"client-key"`
+	// Tenants can only be set in the config file, as a list of tenants with the keys of tenantConfig.
+	Tenants []tenantConfig `mapstructure:"tenants"`
+	// Provisioning mirrors the SDK's --provisioning flag, which is not part of cli.BaseConfig.
+	Provisioning bool `mapstructure:"provisioning"`
+}
+
+// tenantConfig is one Twingate network of a multi-tenant sync.
+type tenantConfig struct {
+	Name       string `mapstructure:"name"`
+	Domain     string `mapstructure:"domain"`
+	ApiKey     string `mapstructure:"api-key"`
+	APIKeyFile string `mapstructure:"api-key-file"`
+	APIURL     string `mapstructure:"api-url"`
 }
 
 // validateConfig is run after the configuration is loaded, and should return an error if it isn't valid.
 func validateConfig(ctx context.Context, cfg *config) error {
-	if cfg.Domain == "" {
+	if _, err := parsePageSizes(cfg.PageSizes); err != nil {
+		return err
+	}
+	if err := cfg.groupFilter().Validate(); err != nil {
+		return err
+	}
+	if err := connector.ValidateResourceTypes(cfg.ResourceTypes); err != nil {
+		return err
+	}
+	if err := cfg.transport().Validate(); err != nil {
+		return err
+	}
+	if cfg.RecordDir != "" && cfg.ReplayDir != "" {
+		return fmt.Errorf("record-dir and replay-dir cannot be used together")
+	}
+	if len(cfg.Tenants) > 0 {
+		return validateTenants(cfg)
+	}
+	// Replayed syncs do not talk to Twingate, so they need no credentials.
+	if cfg.ReplayDir != "" {
+		return nil
+	}
+	return validateCredentials(cfg.Domain, cfg.APIURL, cfg.ApiKey, cfg.APIKeyFile)
+}
+
+// validateCredentials checks the domain and api key options of the connector or of one tenant.
+func validateCredentials(domain, apiURL, apiKey, apiKeyFile string) error {
+	if domain == "" && apiURL == "" {
 		return fmt.Errorf("domain is missing")
 	}
-	if cfg.ApiKey == "" {
+	if domain != "" {
+		if _, err := client.NormalizeDomain(domain); err != nil {
+			return err
+		}
+	}
+	if apiKey != "" && apiKeyFile != "" {
+		return fmt.Errorf("api-key and api-key-file cannot be used together")
+	}
+	if apiKey == "" && apiKeyFile == "" {
 		return fmt.Errorf("api key is missing")
 	}
 	return nil
 }
 
+// validateTenants checks the tenants of a multi-tenant sync, which replace the domain and api key options.
+func validateTenants(cfg *config) error {
+	if cfg.Domain != "" || cfg.APIURL != "" || cfg.ApiKey != "" || cfg.APIKeyFile != "" {
+		return fmt.Errorf("tenants cannot be combined with domain, api-url, api-key or api-key-file")
+	}
+	tenants := cfg.tenants()
+	if err := connector.ValidateTenants(tenants); err != nil {
+		return err
+	}
+	if cfg.ReplayDir != "" {
+		return nil
+	}
+	for i, t := range cfg.Tenants {
+		if err := validateCredentials(t.Domain, t.APIURL, t.ApiKey, t.APIKeyFile); err != nil {
+			return fmt.Errorf("tenant %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// tenants returns the tenants as connector.TenantConfig values.
+func (cfg *config) tenants() []connector.TenantConfig {
+	rv := make([]connector.TenantConfig, 0, len(cfg.Tenants))
+	for _, t := range cfg.Tenants {
+		rv = append(rv, connector.TenantConfig{
+			Name:       t.Name,
+			Domain:     t.Domain,
+			ApiKey:     t.ApiKey,
+			APIKeyFile: t.APIKeyFile,
+			APIURL:     t.APIURL,
+		})
+	}
+	return rv
+}
+
+// groupFilter returns the group filter options as a connector.GroupFilterConfig.
+func (cfg *config) groupFilter() connector.GroupFilterConfig {
+	return connector.GroupFilterConfig{
+		Include:    cfg.GroupInclude,
+		Exclude:    cfg.GroupExclude,
+		IDs:        cfg.GroupIDs,
+		ExcludeIDs: cfg.ExcludeGroupIDs,
+		Types:      cfg.GroupTypes,
+	}
+}
+
+// transport returns the proxy and certificate options as a client.TransportConfig.
+func (cfg *config) transport() client.TransportConfig {
+	return client.TransportConfig{
+		ProxyURL:       cfg.ProxyURL,
+		ProxyUsername:  cfg.ProxyUsername,
+		ProxyPassword:  cfg.ProxyPassword,
+		CAFile:         cfg.CAFile,
+		ClientCertFile: cfg.ClientCert,
+		ClientKeyFile:  cfg.ClientKey,
+	}
+}
+
+// parsePageSizes parses page size overrides given as type=size, such as group-member=500.
+func parsePageSizes(values []string) (map[client.ConnectionType]uint32, error) {
+	rv := make(map[client.ConnectionType]uint32, len(values))
+	for _, value := range values {
+		name, size, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("page-size %q must be of the form type=size", value)
+		}
+		conn, err := client.ParseConnectionType(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(size), 10, 32)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("page-size %q must be a positive number", value)
+		}
+		rv[conn] = uint32(n)
+	}
+	return rv, nil
+}
+
 // cmdFlags sets the cmdFlags required for the connector.
 func cmdFlags(cmd *cobra.Command) {
-	cmd.PersistentFlags().String("domain", "", "The domain for your Twingate account. ($BATON_DOMAIN)")
-	cmd.PersistentFlags().String("api-key", "", "The api key for your Twingate account. ($BATON_API_KEY)")
+	cmd.PersistentFlags().String("domain", "", "The domain for your Twingate account, as a name, hostname or https URL. ($BATON_DOMAIN)")
+	cmd.PersistentFlags().String("api-url", "", "The URL of the Twingate GraphQL API, overriding the one derived from the domain. ($BATON_API_URL)")
+	cmd.PersistentFlags().String("api-key", "", "The api key for your Twingate account, or a reference to it such as env:NAME or file:PATH. ($BATON_API_KEY)")
+	cmd.PersistentFlags().String("api-key-file", "", "The path to a file holding the api key, read again when it changes or the key is rejected. ($BATON_API_KEY_FILE)")
+	cmd.PersistentFlags().Bool("prefetch-group-members", false, "Fetch group members together with the groups list to save one API call per group. ($BATON_PREFETCH_GROUP_MEMBERS)")
+	cmd.PersistentFlags().Bool("incremental-sync", false, "Reuse the group members of the previous sync in the c1z file for groups that did not change. ($BATON_INCREMENTAL_SYNC)")
+	cmd.PersistentFlags().StringSlice("page-size", nil, "Fix the page size of a list instead of adapting it, as type=size with type user, group or group-member. ($BATON_PAGE_SIZE)")
+	cmd.PersistentFlags().StringSlice("resource-types", nil, "Only sync these resource types: group, role or user. All are synced when empty. ($BATON_RESOURCE_TYPES)")
+	cmd.PersistentFlags().String("group-include", "", "Only sync groups whose name matches this regular expression. ($BATON_GROUP_INCLUDE)")
+	cmd.PersistentFlags().String("group-exclude", "", "Do not sync groups whose name matches this regular expression. ($BATON_GROUP_EXCLUDE)")
+	cmd.PersistentFlags().StringSlice("group-ids", nil, "Only sync the groups with these IDs. ($BATON_GROUP_IDS)")
+	cmd.PersistentFlags().StringSlice("exclude-group-ids", nil, "Do not sync the groups with these IDs. ($BATON_EXCLUDE_GROUP_IDS)")
+	cmd.PersistentFlags().StringSlice("group-types", nil, "Only sync groups of these types: manual, synced or system. ($BATON_GROUP_TYPES)")
+	cmd.PersistentFlags().String("identity-provider", "", "The identity provider that syncs users to Twingate, such as Okta, shown for synced users. ($BATON_IDENTITY_PROVIDER)")
+	cmd.PersistentFlags().String("proxy-url", "", "Send requests through this http, https or socks5 proxy instead of the one in $HTTPS_PROXY. ($BATON_PROXY_URL)")
+	cmd.PersistentFlags().String("proxy-username", "", "The username to authenticate with the proxy. ($BATON_PROXY_USERNAME)")
+	cmd.PersistentFlags().String("proxy-password", "", "The password to authenticate with the proxy. ($BATON_PROXY_PASSWORD)")
+	cmd.PersistentFlags().String("ca-file", "", "A PEM file of CA certificates to trust in addition to the system ones, such as that of a TLS inspecting proxy. ($BATON_CA_FILE)")
+	cmd.PersistentFlags().String("client-cert", "", "A PEM client certificate to present to Twingate or the proxy, used with --client-key. ($BATON_CLIENT_CERT)")
+	cmd.PersistentFlags().String("client-key", "", "The PEM private key of --client-cert. ($BATON_CLIENT_KEY)")
+	cmd.PersistentFlags().String("record-dir", "", "Save every Twingate API request and response to this directory, with the api key redacted. ($BATON_RECORD_DIR)")
+	cmd.PersistentFlags().String("replay-dir", "", "Serve Twingate API requests from the recordings in this directory instead of the network. ($BATON_REPLAY_DIR)")
 }
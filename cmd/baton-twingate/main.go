@@ -38,9 +38,26 @@ func main() {
 
 func getConnector(ctx context.Context, cfg *config) (types.ConnectorServer, error) {
 	l := ctxzap.Extract(ctx)
+	pageSizes, err := parsePageSizes(cfg.PageSizes)
+	if err != nil {
+		return nil, err
+	}
 	config := connector.Config{
-		Domain: cfg.Domain,
-		ApiKey: cfg.ApiKey,
+		Domain:               cfg.Domain,
+		ApiKey:               cfg.ApiKey,
+		APIKeyFile:           cfg.APIKeyFile,
+		APIURL:               cfg.APIURL,
+		PrefetchGroupMembers: cfg.PrefetchGroupMembers,
+		IncrementalSync:      cfg.IncrementalSync,
+		RecordDir:            cfg.RecordDir,
+		ReplayDir:            cfg.ReplayDir,
+		PageSizes:            pageSizes,
+		GroupFilter:          cfg.groupFilter(),
+		ResourceTypes:        cfg.ResourceTypes,
+		IdentityProvider:     cfg.IdentityProvider,
+		Transport:            cfg.transport(),
+		Tenants:              cfg.tenants(),
+		Provisioning:         cfg.Provisioning || cfg.GrantEntitlementID != "" || cfg.RevokeGrantID != "",
 	}
 	cb, err := connector.New(ctx, config)
 	if err != nil {
@@ -0,0 +1,103 @@
+// Command twingate-fake serves an in-memory Twingate GraphQL API for local development. Point the connector at it
+// with the client API URL, for example http://127.0.0.1:8080/api/graphql/.
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8080", "The address to listen on.")
+	dataPath := flag.String("data", "", "The path to a JSON dataset with users and groups.")
+	apiKey := flag.String("api-key", "", "If set, requests must send this api key.")
+	readOnly := flag.Bool("read-only", false, "Reject mutations with a permission error, like a read-only api key.")
+	tlsCert := flag.String("tls-cert", "", "Serve https with this PEM certificate, used with -tls-key.")
+	tlsKey := flag.String("tls-key", "", "The PEM private key of -tls-cert.")
+	clientCA := flag.String("client-ca", "", "Require https clients to present a certificate signed by a CA in this PEM file.")
+	proxyAddr := flag.String("proxy-addr", "", "If set, also serve a forward proxy on this address, like an egress proxy.")
+	proxyUsername := flag.String("proxy-username", "", "The username the proxy requires.")
+	proxyPassword := flag.String("proxy-password", "", "The password the proxy requires.")
+	flag.Parse()
+
+	if *clientCA != "" && *tlsCert == "" {
+		fmt.Fprintln(os.Stderr, "twingate-fake: -client-ca needs -tls-cert and -tls-key")
+		os.Exit(1)
+	}
+
+	data := &twingatefake.Dataset{}
+	if *dataPath != "" {
+		var err error
+		data, err = twingatefake.LoadDataset(*dataPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+	}
+
+	var opts []twingatefake.Option
+	if *apiKey != "" {
+		opts = append(opts, twingatefake.WithAPIKey(*apiKey))
+	}
+	if *readOnly {
+		opts = append(opts, twingatefake.WithReadOnly())
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           twingatefake.New(data, opts...),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if *clientCA != "" {
+		pem, err := os.ReadFile(*clientCA)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			fmt.Fprintf(os.Stderr, "twingate-fake: %s holds no PEM certificates\n", *clientCA)
+			os.Exit(1)
+		}
+		server.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientCAs:  pool,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		}
+	}
+
+	if *proxyAddr != "" {
+		proxy := &http.Server{
+			Addr:              *proxyAddr,
+			Handler:           twingatefake.NewProxy(*proxyUsername, *proxyPassword),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		fmt.Fprintf(os.Stderr, "twingate-fake: serving a proxy on http://%s\n", *proxyAddr)
+		go func() {
+			if err := proxy.ListenAndServe(); err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+				os.Exit(1)
+			}
+		}()
+	}
+
+	var err error
+	if *tlsCert != "" {
+		fmt.Fprintf(os.Stderr, "twingate-fake: serving %d users and %d groups on https://%s/api/graphql/\n", len(data.Users), len(data.Groups), *addr)
+		err = server.ListenAndServeTLS(*tlsCert, *tlsKey)
+	} else {
+		fmt.Fprintf(os.Stderr, "twingate-fake: serving %d users and %d groups on http://%s/api/graphql/\n", len(data.Users), len(data.Groups), *addr)
+		err = server.ListenAndServe()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,218 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// apiKeySource holds the API key, either as given or read from a file. The key of a file is kept in memory and read
+// again only when the directory of the file reports a change, or when Twingate rejects the key, so a rotated key is
+// picked up without a restart. Watching the directory rather than the file covers Kubernetes secrets mounted as
+// volumes, which are updated in place by swapping a symlink. Where the directory cannot be watched, the file is
+// checked for a new modification time or size before every request instead.
+//
+// String and GoString redact the key so that it does not end up in logs or error messages when the client is
+// printed.
+type apiKeySource struct {
+	mu   sync.Mutex
+	key  string
+	path string
+	// stale is set when the key file may have changed since it was last read.
+	stale   bool
+	watched bool
+	// watcher watches the directory of the key file until Close is called or the context passed to watch is done.
+	// watchDone is closed once the watch has stopped.
+	watcher   *fsnotify.Watcher
+	watchDone chan struct{}
+	modTime   time.Time
+	size      int64
+}
+
+// API keys may also be given as a reference to where the key is kept: env:NAME reads it from the environment variable
+// NAME, and file:PATH from the file at PATH, like WithAPIKeyFile.
+const (
+	apiKeyEnvRef  = "env:"
+	apiKeyFileRef = "file:"
+)
+
+// newAPIKeySource returns the source of apiKey, resolving it first if it is a reference.
+func newAPIKeySource(apiKey string) (*apiKeySource, error) {
+	switch {
+	case strings.HasPrefix(apiKey, apiKeyEnvRef):
+		name := strings.TrimPrefix(apiKey, apiKeyEnvRef)
+		key := strings.TrimSpace(os.Getenv(name))
+		if key == "" {
+			return nil, fmt.Errorf("twingate-client: api key environment variable %s is not set", name)
+		}
+		return &apiKeySource{key: key}, nil
+	case strings.HasPrefix(apiKey, apiKeyFileRef):
+		return &apiKeySource{path: strings.TrimPrefix(apiKey, apiKeyFileRef)}, nil
+	default:
+		return &apiKeySource{key: apiKey}, nil
+	}
+}
+
+// WithAPIKeyFile reads the API key from the file at path instead of using the key passed to New. Surrounding
+// whitespace, such as a trailing newline, is ignored.
+func WithAPIKeyFile(path string) Option {
+	return func(c *ConnectorClient) {
+		c.apiKey.mu.Lock()
+		defer c.apiKey.mu.Unlock()
+		c.apiKey.key = ""
+		c.apiKey.path = path
+	}
+}
+
+// watch starts watching the directory of the key file for changes. The watch lasts until ctx is done or Close is
+// called, after which the file is checked before every request as when the directory cannot be watched.
+func (s *apiKeySource) watch(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" || s.watched {
+		return
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return
+	}
+	if err := watcher.Add(filepath.Dir(s.path)); err != nil {
+		_ = watcher.Close()
+		return
+	}
+	s.watched = true
+	s.watcher = watcher
+	s.watchDone = make(chan struct{})
+	go s.readEvents(ctx, watcher, s.watchDone)
+}
+
+// readEvents marks the key stale on every event of watcher until ctx is done or watcher is closed.
+func (s *apiKeySource) readEvents(ctx context.Context, watcher *fsnotify.Watcher, done chan struct{}) {
+	defer func() {
+		_ = watcher.Close()
+		s.mu.Lock()
+		s.watched = false
+		s.mu.Unlock()
+		close(done)
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			s.markStale()
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			// Events may have been dropped.
+			s.markStale()
+		}
+	}
+}
+
+// Close stops watching the key file and waits for the watch to end.
+func (s *apiKeySource) Close() error {
+	s.mu.Lock()
+	watcher, done := s.watcher, s.watchDone
+	s.watcher, s.watchDone = nil, nil
+	s.mu.Unlock()
+	if watcher == nil {
+		return nil
+	}
+	err := watcher.Close()
+	<-done
+	return err
+}
+
+func (s *apiKeySource) markStale() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stale = true
+}
+
+// get returns the current API key, reading the key file again if it may have changed.
+func (s *apiKeySource) get() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" || (s.key != "" && s.watched && !s.stale) {
+		return s.key, nil
+	}
+	if s.key != "" && !s.watched {
+		info, err := os.Stat(s.path)
+		if err != nil {
+			return "", fmt.Errorf("twingate-client: error reading api key file: %w", err)
+		}
+		if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+			return s.key, nil
+		}
+	}
+	return s.read()
+}
+
+// reload reads the key file again after Twingate rejected the key, and returns the key read. Keys that do not come
+// from a file are returned as they are.
+func (s *apiKeySource) reload() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" {
+		return s.key, nil
+	}
+	return s.read()
+}
+
+// read reads the key file. s.mu must be held.
+func (s *apiKeySource) read() (string, error) {
+	// Clear stale first, so that a change made while the file is read is seen by the next get.
+	s.stale = false
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", fmt.Errorf("twingate-client: error reading api key file: %w", err)
+	}
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("twingate-client: error reading api key file: %w", err)
+	}
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return "", fmt.Errorf("twingate-client: api key file %s is empty", s.path)
+	}
+	s.key = key
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return s.key, nil
+}
+
+// current returns the last API key that was read, without touching the key file.
+func (s *apiKeySource) current() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.key
+}
+
+// redact replaces the API key in b.
+func (s *apiKeySource) redact(b []byte) []byte {
+	key := s.current()
+	if key == "" {
+		return b
+	}
+	return bytes.ReplaceAll(b, []byte(key), []byte(redacted))
+}
+
+func (s *apiKeySource) String() string {
+	return redacted
+}
+
+func (s *apiKeySource) GoString() string {
+	return redacted
+}
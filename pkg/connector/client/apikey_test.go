@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+)
+
+// rotatingKeyServer serves a fake Twingate that accepts one API key at a time.
+type rotatingKeyServer struct {
+	mu  sync.Mutex
+	key string
+}
+
+func (s *rotatingKeyServer) setKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+}
+
+func (s *rotatingKeyServer) start(t *testing.T) string {
+	fake := twingatefake.New(testDataset(1))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		key := s.key
+		s.mu.Unlock()
+		if r.Header.Get("X-API-KEY") != key {
+			http.Error(w, "invalid api key", http.StatusUnauthorized)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/api/graphql/"
+}
+
+func writeKey(t *testing.T, path string, key string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(key+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAPIKeyReferences(t *testing.T) {
+	ctx := context.Background()
+	server := &rotatingKeyServer{key: testAPIKey}
+	apiURL := server.start(t)
+	path := filepath.Join(t.TempDir(), "api-key")
+	writeKey(t, path, testAPIKey)
+	t.Setenv("TEST_TWINGATE_API_KEY", testAPIKey)
+
+	for _, ref := range []string{"env:TEST_TWINGATE_API_KEY", "file:" + path} {
+		c, err := New(ctx, ref, "example", WithAPIURL(apiURL), WithRetryPolicy(RetryPolicy{}))
+		if err != nil {
+			t.Fatalf("%s: %v", ref, err)
+		}
+		if _, err := c.ListUsers(ctx, "", 10); err != nil {
+			t.Errorf("%s: %v", ref, err)
+		}
+	}
+
+	if _, err := New(ctx, "env:TEST_TWINGATE_MISSING", "example", WithAPIURL(apiURL)); err == nil {
+		t.Error("a reference to a missing environment variable was accepted")
+	}
+	if _, err := New(ctx, "file:"+path+".missing", "example", WithAPIURL(apiURL)); err == nil {
+		t.Error("a reference to a missing file was accepted")
+	}
+}
+
+func TestAPIKeyFileRotation(t *testing.T) {
+	ctx := context.Background()
+	server := &rotatingKeyServer{key: "old-key"}
+	apiURL := server.start(t)
+	path := filepath.Join(t.TempDir(), "api-key")
+	writeKey(t, path, "old-key")
+
+	c, err := New(ctx, "", "example", WithAPIKeyFile(path), WithAPIURL(apiURL), WithRetryPolicy(RetryPolicy{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListUsers(ctx, "", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// Twingate revoked the key before the file was updated.
+	server.setKey("new-key")
+	if _, err := c.ListUsers(ctx, "", 10); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("got error %v, want ErrUnauthorized", err)
+	}
+
+	// Whether the change of the file or the rejected request is seen first, the new key is used without a restart.
+	writeKey(t, path, "new-key")
+	if _, err := c.ListUsers(ctx, "", 10); err != nil {
+		t.Fatal(err)
+	}
+	if key := c.apiKey.current(); key != "new-key" {
+		t.Errorf("client uses key %q after the rotation", key)
+	}
+}
+
+func TestAPIKeyFileIsReadOnlyWhenStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-key")
+	writeKey(t, path, "first-key")
+	s, err := newAPIKeySource("file:" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.get(); err != nil {
+		t.Fatal(err)
+	}
+	// A watched key is not checked against the file until the watch reports a change.
+	s.watched = true
+	writeKey(t, path, "second-key")
+	if key, _ := s.get(); key != "first-key" {
+		t.Errorf("got key %q before the change was reported", key)
+	}
+	s.markStale()
+	if key, _ := s.get(); key != "second-key" {
+		t.Errorf("got key %q after the change was reported", key)
+	}
+}
+
+// watching reports whether the key file of c is still watched.
+func watching(c *ConnectorClient) bool {
+	c.apiKey.mu.Lock()
+	defer c.apiKey.mu.Unlock()
+	return c.apiKey.watched
+}
+
+func TestAPIKeyWatchEnds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-key")
+	writeKey(t, path, "first-key")
+	newClient := func(ctx context.Context) *ConnectorClient {
+		c, err := New(ctx, "", "example", WithAPIKeyFile(path), WithAPIURL("http://127.0.0.1/api/graphql/"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !watching(c) {
+			t.Fatal("the key file is not watched")
+		}
+		return c
+	}
+
+	c := newClient(context.Background())
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if watching(c) {
+		t.Error("the key file is still watched after Close")
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("closing the client again: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c = newClient(ctx)
+	done := c.apiKey.watchDone
+	cancel()
+	<-done
+	if watching(c) {
+		t.Error("the key file is still watched after the context is done")
+	}
+	// Without the watch, the file is checked before every request.
+	writeKey(t, path, "second-key-of-another-size")
+	if key, err := c.apiKey.get(); err != nil || key != "second-key-of-another-size" {
+		t.Errorf("got key %q, %v after the watch ended, want the new key", key, err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,363 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+)
+
+// GroupMembershipBatchSize is the default number of users sent in a single groupUpdate mutation.
+const GroupMembershipBatchSize = 100
+
+// MembershipResult is the outcome of a single queued membership change.
+type MembershipResult struct {
+	GroupID string
+	UserID  string
+	Added   bool
+	Err     error
+}
+
+type GroupMembershipBatchResponse struct {
+	Results              []MembershipResult
+	RateLimitDescription *v2.RateLimitDescription
+}
+
+type groupMembershipChanges struct {
+	// changes maps a user ID to true when the user is added and false when it is removed.
+	changes map[string]bool
+	order   []string
+}
+
+// GroupMembershipBatch queues group membership changes and applies them with one groupUpdate mutation per group
+// and batch of users, instead of one mutation per user.
+type GroupMembershipBatch struct {
+	client    *ConnectorClient
+	batchSize int
+	groups    map[string]*groupMembershipChanges
+	order     []string
+}
+
+// NewGroupMembershipBatch returns an empty batch. A batchSize of zero or less uses GroupMembershipBatchSize.
+func (c *ConnectorClient) NewGroupMembershipBatch(batchSize int) *GroupMembershipBatch {
+	if batchSize <= 0 {
+		batchSize = GroupMembershipBatchSize
+	}
+	return &GroupMembershipBatch{
+		client:    c,
+		batchSize: batchSize,
+		groups:    make(map[string]*groupMembershipChanges),
+	}
+}
+
+// Add queues adding userID to groupID.
+func (b *GroupMembershipBatch) Add(groupID string, userID string) {
+	b.queue(groupID, userID, true)
+}
+
+// Remove queues removing userID from groupID.
+func (b *GroupMembershipBatch) Remove(groupID string, userID string) {
+	b.queue(groupID, userID, false)
+}
+
+// Len returns the number of queued changes.
+func (b *GroupMembershipBatch) Len() int {
+	n := 0
+	for _, g := range b.groups {
+		n += len(g.order)
+	}
+	return n
+}
+
+// queued returns the change queued for groupID and userID, if any.
+func (b *GroupMembershipBatch) queued(groupID string, userID string) (bool, bool) {
+	g, ok := b.groups[groupID]
+	if !ok {
+		return false, false
+	}
+	added, ok := g.changes[userID]
+	return added, ok
+}
+
+// queue records a change. A later change for the same group and user replaces the earlier one.
+func (b *GroupMembershipBatch) queue(groupID string, userID string, added bool) {
+	g, ok := b.groups[groupID]
+	if !ok {
+		g = &groupMembershipChanges{changes: make(map[string]bool)}
+		b.groups[groupID] = g
+		b.order = append(b.order, groupID)
+	}
+	if _, ok := g.changes[userID]; !ok {
+		g.order = append(g.order, userID)
+	}
+	g.changes[userID] = added
+}
+
+// unqueue drops the change queued for groupID and userID, if any.
+func (b *GroupMembershipBatch) unqueue(groupID string, userID string) {
+	g, ok := b.groups[groupID]
+	if !ok {
+		return
+	}
+	if _, ok := g.changes[userID]; !ok {
+		return
+	}
+	delete(g.changes, userID)
+	g.order = without(g.order, userID)
+	if len(g.order) == 0 {
+		delete(b.groups, groupID)
+		b.order = without(b.order, groupID)
+	}
+}
+
+// without returns ids without the first occurrence of id.
+func without(ids []string, id string) []string {
+	for i, v := range ids {
+		if v == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
+
+// Flush sends all queued changes and empties the batch. When a batched mutation is rejected, its changes are
+// retried one user at a time, so that each result carries the error of that user only. Changes that could not be
+// sent because of the rate limit fail with an error wrapping ErrRateLimited and are not retried.
+// An error is returned only if the context is done; per-user failures are reported in the results.
+func (b *GroupMembershipBatch) Flush(ctx context.Context) (*GroupMembershipBatchResponse, error) {
+	rv := &GroupMembershipBatchResponse{}
+	for _, groupID := range b.order {
+		g := b.groups[groupID]
+		for start := 0; start < len(g.order); start += b.batchSize {
+			end := start + b.batchSize
+			if end > len(g.order) {
+				end = len(g.order)
+			}
+
+			var added, removed []string
+			for _, userID := range g.order[start:end] {
+				if g.changes[userID] {
+					added = append(added, userID)
+				} else {
+					removed = append(removed, userID)
+				}
+			}
+
+			results, rateLimitDescription, err := b.flushChunk(ctx, groupID, added, removed)
+			if err != nil {
+				return nil, err
+			}
+			rv.Results = append(rv.Results, results...)
+			if rateLimitDescription != nil {
+				rv.RateLimitDescription = rateLimitDescription
+			}
+		}
+	}
+
+	b.groups = make(map[string]*groupMembershipChanges)
+	b.order = nil
+	return rv, nil
+}
+
+func (b *GroupMembershipBatch) flushChunk(ctx context.Context, groupID string, added []string, removed []string) ([]MembershipResult, *v2.RateLimitDescription, error) {
+	rateLimitDescription, err := b.client.updateGroupMembers(ctx, groupID, added, removed)
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+	if err == nil || len(added)+len(removed) == 1 || errors.Is(err, ErrRateLimited) {
+		// A rate limited batch says nothing about its changes, and sending them one by one would only be rate
+		// limited too.
+		return membershipResults(groupID, added, removed, err), rateLimitDescription, nil
+	}
+
+	// The batch was rejected as a whole; apply it user by user to find out which changes fail.
+	results := make([]MembershipResult, 0, len(added)+len(removed))
+	var rateLimitErr error
+	apply := func(userID string, isAdded bool) error {
+		if rateLimitErr != nil {
+			results = append(results, MembershipResult{GroupID: groupID, UserID: userID, Added: isAdded, Err: rateLimitErr})
+			return nil
+		}
+		var rl *v2.RateLimitDescription
+		var err error
+		if isAdded {
+			rl, err = b.client.updateGroupMembers(ctx, groupID, []string{userID}, nil)
+		} else {
+			rl, err = b.client.updateGroupMembers(ctx, groupID, nil, []string{userID})
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if rl != nil {
+			rateLimitDescription = rl
+		}
+		if errors.Is(err, ErrRateLimited) {
+			rateLimitErr = err
+		}
+		results = append(results, MembershipResult{GroupID: groupID, UserID: userID, Added: isAdded, Err: err})
+		return nil
+	}
+	for _, userID := range added {
+		if err := apply(userID, true); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, userID := range removed {
+		if err := apply(userID, false); err != nil {
+			return nil, nil, err
+		}
+	}
+	return results, rateLimitDescription, nil
+}
+
+func membershipResults(groupID string, added []string, removed []string, err error) []MembershipResult {
+	results := make([]MembershipResult, 0, len(added)+len(removed))
+	for _, userID := range added {
+		results = append(results, MembershipResult{GroupID: groupID, UserID: userID, Added: true, Err: err})
+	}
+	for _, userID := range removed {
+		results = append(results, MembershipResult{GroupID: groupID, UserID: userID, Added: false, Err: err})
+	}
+	return results
+}
+
+// updateGroupMembers sends a single groupUpdate mutation adding and removing the given users.
+func (c *ConnectorClient) updateGroupMembers(ctx context.Context, groupID string, added []string, removed []string) (*v2.RateLimitDescription, error) {
+	gql := c.graphql()
+	resp, err := updateGroupMembers(ctx, gql, groupID, added, removed)
+	if err != nil {
+		return RateLimitDescription(err), fmt.Errorf("twingate-client: error updating members of group %s for %s: %w", groupID, c.Domain, err)
+	}
+	if !resp.GroupUpdate.Ok {
+		return gql.rateLimitDescription, apiError(resp.GroupUpdate.Error, fmt.Sprintf("unable to update members of group %s", groupID))
+	}
+	return gql.rateLimitDescription, nil
+}
+
+// membershipKey identifies the change of one user in one group.
+type membershipKey struct {
+	groupID string
+	userID  string
+}
+
+// membershipQueue sends the membership changes of GrantGroupMembership and RevokeGroupMembership through a
+// GroupMembershipBatch. A change is sent right away when no other is in flight. Changes requested while a batch is
+// being sent wait and go out together in the next batch, so concurrent grants and revokes share groupUpdate
+// mutations instead of each costing one.
+type membershipQueue struct {
+	client *ConnectorClient
+
+	mu       sync.Mutex
+	next     *queuedBatch
+	flushing bool
+}
+
+// queuedBatch is a batch of changes and, once done is closed, its results.
+type queuedBatch struct {
+	batch *GroupMembershipBatch
+	// waiters counts the callers waiting for each change, since callers asking for the same change share it.
+	waiters              map[membershipKey]int
+	done                 chan struct{}
+	results              map[membershipKey]MembershipResult
+	rateLimitDescription *v2.RateLimitDescription
+	err                  error
+}
+
+func newMembershipQueue(c *ConnectorClient) *membershipQueue {
+	return &membershipQueue{client: c}
+}
+
+// apply queues adding or removing userID in groupID and waits for the batch that sends it. The caller that finds no
+// batch in flight sends the queued batches with its context until none are left.
+func (q *membershipQueue) apply(ctx context.Context, groupID string, userID string, added bool) (MembershipResult, *v2.RateLimitDescription, error) {
+	for {
+		q.mu.Lock()
+		if q.next == nil {
+			q.next = &queuedBatch{
+				batch:   q.client.NewGroupMembershipBatch(0),
+				waiters: make(map[membershipKey]int),
+				done:    make(chan struct{}),
+			}
+		}
+		b := q.next
+		if queuedAdded, ok := b.batch.queued(groupID, userID); ok && queuedAdded != added {
+			// The opposite change is waiting to be sent. It is applied first rather than replaced, so that both
+			// callers get the outcome of their own change.
+			q.mu.Unlock()
+			select {
+			case <-b.done:
+				continue
+			case <-ctx.Done():
+				return MembershipResult{}, nil, ctx.Err()
+			}
+		}
+		key := membershipKey{groupID: groupID, userID: userID}
+		b.batch.queue(groupID, userID, added)
+		b.waiters[key]++
+		leader := !q.flushing
+		q.flushing = true
+		q.mu.Unlock()
+
+		if leader {
+			q.flush(ctx)
+		}
+		select {
+		case <-b.done:
+		case <-ctx.Done():
+			if q.withdraw(b, key) {
+				return MembershipResult{}, nil, ctx.Err()
+			}
+			// The batch is already being sent and may apply the change, so the caller waits for its outcome
+			// rather than failing for a change that was made.
+			<-b.done
+		}
+		if b.err != nil {
+			return MembershipResult{}, b.rateLimitDescription, b.err
+		}
+		return b.results[key], b.rateLimitDescription, nil
+	}
+}
+
+// withdraw takes back the change of a caller that stopped waiting for b, and reports whether it could. A change that
+// other callers still wait for stays queued, and one that is already being sent cannot be taken back.
+func (q *membershipQueue) withdraw(b *queuedBatch, key membershipKey) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.next != b {
+		return false
+	}
+	b.waiters[key]--
+	if b.waiters[key] == 0 {
+		delete(b.waiters, key)
+		b.batch.unqueue(key.groupID, key.userID)
+	}
+	return true
+}
+
+// flush sends queued batches until no changes are left.
+func (q *membershipQueue) flush(ctx context.Context) {
+	for {
+		q.mu.Lock()
+		b := q.next
+		q.next = nil
+		if b == nil {
+			q.flushing = false
+			q.mu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+
+		resp, err := b.batch.Flush(ctx)
+		b.err = err
+		if resp != nil {
+			b.rateLimitDescription = resp.RateLimitDescription
+			b.results = make(map[membershipKey]MembershipResult, len(resp.Results))
+			for _, r := range resp.Results {
+				b.results[membershipKey{groupID: r.GroupID, userID: r.UserID}] = r
+			}
+		}
+		close(b.done)
+	}
+}
@@ -0,0 +1,424 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+)
+
+const testGroupID = "R3JvdXA6MQ=="
+
+func TestGroupMembershipBatchFlush(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(250))
+
+	b := c.NewGroupMembershipBatch(100)
+	for i := 0; i < 250; i++ {
+		b.Add(testGroupID, fmt.Sprintf("User:%d", i))
+	}
+	resp, err := b.Flush(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 250 {
+		t.Fatalf("got %d results, want 250", len(resp.Results))
+	}
+	for _, r := range resp.Results {
+		if r.Err != nil {
+			t.Errorf("adding %s: %v", r.UserID, r.Err)
+		}
+	}
+	if calls := fake.Calls("updateGroupMembers"); calls != 3 {
+		t.Errorf("sent %d mutations, want 3", calls)
+	}
+	if members := groupMembers(t, c, testGroupID); len(members) != 250 {
+		t.Errorf("group has %d members, want 250", len(members))
+	}
+	if b.Len() != 0 {
+		t.Errorf("flushed batch still holds %d changes", b.Len())
+	}
+}
+
+func TestGroupMembershipBatchSplitsRejectedBatch(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(2))
+
+	b := c.NewGroupMembershipBatch(0)
+	b.Add(testGroupID, "User:0")
+	b.Add(testGroupID, "User:missing")
+	b.Add(testGroupID, "User:1")
+	resp, err := b.Flush(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range resp.Results {
+		if (r.Err != nil) != (r.UserID == "User:missing") {
+			t.Errorf("result of %s has error %v", r.UserID, r.Err)
+		}
+	}
+	// The rejected batch, then one mutation per user.
+	if calls := fake.Calls("updateGroupMembers"); calls != 4 {
+		t.Errorf("sent %d mutations, want 4", calls)
+	}
+	if members := groupMembers(t, c, testGroupID); !members["User:0"] || !members["User:1"] {
+		t.Errorf("group members are %v, want User:0 and User:1", members)
+	}
+}
+
+func TestGroupMembershipBatchRateLimited(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(3))
+	fake.InjectFault(twingatefake.Fault{Operation: "updateGroupMembers", StatusCode: http.StatusTooManyRequests, Message: "rate limited"})
+
+	b := c.NewGroupMembershipBatch(0)
+	for i := 0; i < 3; i++ {
+		b.Add(testGroupID, fmt.Sprintf("User:%d", i))
+	}
+	resp, err := b.Flush(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range resp.Results {
+		if !errors.Is(r.Err, ErrRateLimited) {
+			t.Errorf("result of %s has error %v, want ErrRateLimited", r.UserID, r.Err)
+		}
+	}
+	if resp.RateLimitDescription == nil {
+		t.Error("rate limited batch has no rate limit description")
+	}
+	// A rate limited batch is not split into one mutation per user.
+	if calls := fake.Calls("updateGroupMembers"); calls != 1 {
+		t.Errorf("sent %d mutations, want 1", calls)
+	}
+}
+
+func TestGrantGroupMembershipRateLimited(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(1))
+	fake.InjectFault(twingatefake.Fault{Operation: "updateGroupMembers", StatusCode: http.StatusTooManyRequests, Message: "rate limited"})
+
+	resp, err := c.GrantGroupMembership(ctx, testGroupID, "User:0")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("got error %v, want ErrRateLimited", err)
+	}
+	if resp == nil || resp.RateLimitDescription == nil {
+		t.Error("rate limited grant has no rate limit description")
+	}
+}
+
+// blockingTransport holds the first membership mutation until release is closed.
+type blockingTransport struct {
+	next    http.RoundTripper
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+func (t *blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if !bytes.Contains(body, []byte("mutation updateGroupMembers")) {
+		return t.next.RoundTrip(req)
+	}
+	first := false
+	t.once.Do(func() { first = true })
+	if first {
+		close(t.started)
+		<-t.release
+	}
+	return t.next.RoundTrip(req)
+}
+
+func TestConcurrentGrantsShareMutations(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(5))
+	transport := &blockingTransport{next: c.Client.Transport, started: make(chan struct{}), release: make(chan struct{})}
+	c.Client.Transport = transport
+
+	errs := make(chan error, 5)
+	grant := func(userID string) {
+		_, err := c.GrantGroupMembership(ctx, testGroupID, userID)
+		errs <- err
+	}
+	go grant("User:0")
+	<-transport.started
+
+	// These grants queue up while the first one is in flight, and go out together once it is done.
+	for i := 1; i < 5; i++ {
+		go grant(fmt.Sprintf("User:%d", i))
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.memberships.mu.Lock()
+		queued := 0
+		if c.memberships.next != nil {
+			queued = c.memberships.next.batch.Len()
+		}
+		c.memberships.mu.Unlock()
+		if queued == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d grants queued, want 4", queued)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(transport.release)
+
+	for i := 0; i < 5; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	if calls := fake.Calls("updateGroupMembers"); calls != 2 {
+		t.Errorf("sent %d mutations, want 2", calls)
+	}
+	if members := groupMembers(t, c, testGroupID); len(members) != 5 {
+		t.Errorf("group has %d members, want 5", len(members))
+	}
+}
+
+func TestOppositeChangesAreNotMerged(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(2))
+	transport := &blockingTransport{next: c.Client.Transport, started: make(chan struct{}), release: make(chan struct{})}
+	c.Client.Transport = transport
+
+	errs := make(chan error, 3)
+	apply := func(userID string, added bool) {
+		result, _, err := c.memberships.apply(ctx, testGroupID, userID, added)
+		if err == nil {
+			err = result.Err
+		}
+		errs <- err
+	}
+	go apply("User:1", true)
+	<-transport.started
+
+	go apply("User:0", true)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.memberships.mu.Lock()
+		queued := c.memberships.next != nil && c.memberships.next.batch.Len() == 1
+		c.memberships.mu.Unlock()
+		if queued {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("grant was not queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// The revoke waits for the queued grant of the same user to be sent instead of replacing it.
+	go apply("User:0", false)
+	time.Sleep(10 * time.Millisecond)
+	close(transport.release)
+
+	for i := 0; i < 3; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	if calls := fake.Calls("updateGroupMembers"); calls != 3 {
+		t.Errorf("sent %d mutations, want 3", calls)
+	}
+	if members := groupMembers(t, c, testGroupID); !members["User:1"] || members["User:0"] {
+		t.Errorf("group members are %v, want User:1 only", members)
+	}
+}
+
+func TestGrantAndRevokeCheckMembershipFirst(t *testing.T) {
+	ctx := context.Background()
+	data := testDataset(2)
+	data.Groups[0].UserIDs = []string{"User:0"}
+	c, fake := newTestClient(t, data)
+
+	grant, err := c.GrantGroupMembership(ctx, testGroupID, "User:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !grant.AlreadyExists {
+		t.Error("granting an existing membership is not reported as already existing")
+	}
+	revoke, err := c.RevokeGroupMembership(ctx, testGroupID, "User:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !revoke.AlreadyRevoked {
+		t.Error("revoking a missing membership is not reported as already revoked")
+	}
+	revoke, err = c.RevokeGroupMembership(ctx, testGroupID, "User:deleted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !revoke.AlreadyRevoked {
+		t.Error("revoking the membership of a missing user is not reported as already revoked")
+	}
+	if calls := fake.Calls("updateGroupMembers"); calls != 0 {
+		t.Errorf("sent %d mutations for memberships that needed no change", calls)
+	}
+
+	if _, err := c.GrantGroupMembership(ctx, testGroupID, "User:deleted"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("granting a missing user returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestRevokeRateLimitedMembershipCheck(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(1))
+	fake.InjectFault(twingatefake.Fault{Operation: "getUserGroups", StatusCode: http.StatusTooManyRequests, Message: "rate limited"})
+
+	resp, err := c.RevokeGroupMembership(ctx, testGroupID, "User:0")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("got error %v, want ErrRateLimited", err)
+	}
+	if resp.RateLimitDescription.GetStatus() != v2.RateLimitDescription_STATUS_OVERLIMIT {
+		t.Errorf("rate limit description is %v, want OVERLIMIT", resp.RateLimitDescription)
+	}
+}
+
+// heldTransport holds every membership mutation until a value is sent on release, and signals started when one
+// is held.
+type heldTransport struct {
+	next    http.RoundTripper
+	started chan struct{}
+	release chan struct{}
+}
+
+func newHeldTransport(next http.RoundTripper) *heldTransport {
+	return &heldTransport{next: next, started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (t *heldTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if bytes.Contains(body, []byte("mutation updateGroupMembers")) {
+		t.started <- struct{}{}
+		<-t.release
+	}
+	return t.next.RoundTrip(req)
+}
+
+// waitForQueued waits until n changes are queued behind the batch in flight.
+func waitForQueued(t *testing.T, c *ConnectorClient, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.memberships.mu.Lock()
+		queued := 0
+		if c.memberships.next != nil {
+			queued = c.memberships.next.batch.Len()
+		}
+		c.memberships.mu.Unlock()
+		if queued == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d changes queued, want %d", queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCancelledChangeIsWithdrawn(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(3))
+	transport := newHeldTransport(c.Client.Transport)
+	c.Client.Transport = transport
+
+	errs := make(chan error, 4)
+	grant := func(ctx context.Context, userID string) {
+		result, _, err := c.memberships.apply(ctx, testGroupID, userID, true)
+		if err == nil {
+			err = result.Err
+		}
+		errs <- err
+	}
+	go grant(ctx, "User:0")
+	<-transport.started
+
+	// User:1 is granted by two callers and User:2 by one, and one caller of each gives up while they are queued.
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	go grant(cancelledCtx, "User:1")
+	go grant(ctx, "User:1")
+	go grant(cancelledCtx, "User:2")
+	waitForQueued(t, c, 2)
+	cancel()
+	for i := 0; i < 2; i++ {
+		if err := <-errs; !errors.Is(err, context.Canceled) {
+			t.Errorf("cancelled grant returned %v, want context.Canceled", err)
+		}
+	}
+	waitForQueued(t, c, 1)
+
+	close(transport.release)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	if calls := fake.Calls("updateGroupMembers"); calls != 2 {
+		t.Errorf("sent %d mutations, want 2", calls)
+	}
+	if members := groupMembers(t, c, testGroupID); !members["User:0"] || !members["User:1"] || members["User:2"] {
+		t.Errorf("group members are %v, want User:0 and User:1", members)
+	}
+}
+
+func TestCancelledChangeInFlightGetsItsOutcome(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t, testDataset(2))
+	transport := newHeldTransport(c.Client.Transport)
+	c.Client.Transport = transport
+
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := c.GrantGroupMembership(ctx, testGroupID, "User:0")
+		leaderErr <- err
+	}()
+	<-transport.started
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	type outcome struct {
+		result MembershipResult
+		err    error
+	}
+	outcomes := make(chan outcome, 1)
+	go func() {
+		result, _, err := c.memberships.apply(cancelledCtx, testGroupID, "User:1", true)
+		outcomes <- outcome{result, err}
+	}()
+	waitForQueued(t, c, 1)
+	transport.release <- struct{}{}
+	// The batch with User:1 is being sent when its caller gives up.
+	<-transport.started
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	transport.release <- struct{}{}
+
+	o := <-outcomes
+	if o.err != nil || o.result.Err != nil || !o.result.Added {
+		t.Errorf("grant cancelled while in flight returned %+v and %v, want its successful result", o.result, o.err)
+	}
+	if err := <-leaderErr; err != nil {
+		t.Error(err)
+	}
+	if members := groupMembers(t, c, testGroupID); !members["User:1"] {
+		t.Errorf("group members are %v, want User:1 among them", members)
+	}
+}
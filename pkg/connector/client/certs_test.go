@@ -0,0 +1,86 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// clientCertificate is a self-signed client certificate written to PEM files, which the fake is configured to
+// require.
+type clientCertificate struct {
+	certFile string
+	keyFile  string
+	pool     *x509.CertPool
+}
+
+func newClientCertificate(dir string) (*clientCertificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "baton-twingate-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := &clientCertificate{
+		certFile: filepath.Join(dir, "client.pem"),
+		keyFile:  filepath.Join(dir, "client-key.pem"),
+		pool:     x509.NewCertPool(),
+	}
+	rv.pool.AddCert(cert)
+	if err := os.WriteFile(rv.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(rv.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		return nil, err
+	}
+	return rv, nil
+}
+
+// startTLS starts server with https, requiring the client certificate.
+func (c *clientCertificate) startTLS(server *httptest.Server) {
+	server.TLS = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientCAs:  c.pool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+	}
+	server.StartTLS()
+}
+
+// writeCAFile writes the certificates of servers to path, so that the client trusts them.
+func writeCAFile(path string, servers []*httptest.Server) error {
+	var b []byte
+	for _, server := range servers {
+		b = append(b, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})...)
+	}
+	return os.WriteFile(path, b, 0o600)
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+)
+
+// ErrRateLimited is wrapped by the errors of requests that Twingate still rejects because of its rate limit once the
+// retries are used up.
+var ErrRateLimited = errors.New("twingate-client: rate limited")
+
+// rateLimitError carries the rate limit description of a request rejected because of the rate limit, so that it
+// reaches callers through the errors that wrap it.
+type rateLimitError struct {
+	rateLimitDescription *v2.RateLimitDescription
+	err                  error
+}
+
+func (e *rateLimitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *rateLimitError) Unwrap() error {
+	return e.err
+}
+
+// RateLimitDescription returns the rate limit description of an error wrapping ErrRateLimited, or nil for other
+// errors. Its ResetAt follows the Retry-After header of the rejected request when there was one.
+func RateLimitDescription(err error) *v2.RateLimitDescription {
+	var rle *rateLimitError
+	if errors.As(err, &rle) {
+		return rle.rateLimitDescription
+	}
+	return nil
+}
+
+// Connection is one page of a cursor-paginated Twingate list, with its nodes converted to client types.
+type Connection[T any] struct {
+	Nodes []T
+	// Pagination is the cursor of the next page, or empty on the last page.
+	Pagination           string
+	RateLimitDescription *v2.RateLimitDescription
+}
+
+// pageInfo is implemented by the pageInfo types generated for every connection.
+type pageInfo interface {
+	GetEndCursor() string
+	GetHasNextPage() bool
+}
+
+// newConnection decodes the edges and page info of a generated connection type. node converts an edge into the
+// client type of its node.
+func newConnection[E any, T any](edges []E, info pageInfo, rateLimitDescription *v2.RateLimitDescription, node func(E) T) *Connection[T] {
+	rv := &Connection[T]{
+		Nodes:                make([]T, 0, len(edges)),
+		RateLimitDescription: rateLimitDescription,
+	}
+	for _, edge := range edges {
+		rv.Nodes = append(rv.Nodes, node(edge))
+	}
+	if info.GetHasNextPage() {
+		rv.Pagination = info.GetEndCursor()
+	}
+	return rv
+}
+
+// PageFunc fetches the page of pageSize nodes that follows the cursor pagination. An empty cursor fetches the first
+// page.
+type PageFunc[T any] func(ctx context.Context, pagination string, pageSize uint32) (*Connection[T], error)
+
+// Each calls fn for every node of a connection, fetching pages until the last one, and returns the rate limit
+// description of the last page. It stops at the first error returned by fetch or fn, so a page rejected because of
+// the rate limit ends it with an error wrapping ErrRateLimited and a partial list is never mistaken for a complete
+// one.
+func Each[T any](ctx context.Context, fetch PageFunc[T], pageSize uint32, fn func(T) error) (*v2.RateLimitDescription, error) {
+	var rateLimitDescription *v2.RateLimitDescription
+	pagination := ""
+	for {
+		page, err := fetch(ctx, pagination, pageSize)
+		if err != nil {
+			if rl := RateLimitDescription(err); rl != nil {
+				rateLimitDescription = rl
+			}
+			return rateLimitDescription, err
+		}
+		if page.RateLimitDescription != nil {
+			rateLimitDescription = page.RateLimitDescription
+		}
+		for _, node := range page.Nodes {
+			if err := fn(node); err != nil {
+				return rateLimitDescription, err
+			}
+		}
+		if page.Pagination == "" {
+			return rateLimitDescription, nil
+		}
+		pagination = page.Pagination
+	}
+}
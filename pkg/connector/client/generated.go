@@ -0,0 +1,1740 @@
+// Code generated by github.com/Khan/genqlient, DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+
+	"github.com/Khan/genqlient/graphql"
+)
+
+type GroupType string
+
+const (
+	GroupTypeManual GroupType = "MANUAL"
+	GroupTypeSynced GroupType = "SYNCED"
+	GroupTypeSystem GroupType = "SYSTEM"
+)
+
+type UserRole string
+
+const (
+	UserRoleAdmin   UserRole = "ADMIN"
+	UserRoleDevops  UserRole = "DEVOPS"
+	UserRoleSupport UserRole = "SUPPORT"
+	UserRoleMember  UserRole = "MEMBER"
+)
+
+type UserStateUpdateInput string
+
+const (
+	UserStateUpdateInputActive   UserStateUpdateInput = "ACTIVE"
+	UserStateUpdateInputDisabled UserStateUpdateInput = "DISABLED"
+)
+
+type UserStateValue string
+
+const (
+	UserStateValuePending  UserStateValue = "PENDING"
+	UserStateValueActive   UserStateValue = "ACTIVE"
+	UserStateValueDisabled UserStateValue = "DISABLED"
+)
+
+type UserType string
+
+const (
+	UserTypeManual UserType = "MANUAL"
+	UserTypeSynced UserType = "SYNCED"
+)
+
+// __createGroupInput is used internally by genqlient
+type __createGroupInput struct {
+	Name        string   `json:"name"`
+	UserIds     []string `json:"userIds,omitempty"`
+	ResourceIds []string `json:"resourceIds,omitempty"`
+}
+
+// GetName returns __createGroupInput.Name, and is useful for accessing the field via an interface.
+func (v *__createGroupInput) GetName() string { return v.Name }
+
+// GetUserIds returns __createGroupInput.UserIds, and is useful for accessing the field via an interface.
+func (v *__createGroupInput) GetUserIds() []string { return v.UserIds }
+
+// GetResourceIds returns __createGroupInput.ResourceIds, and is useful for accessing the field via an interface.
+func (v *__createGroupInput) GetResourceIds() []string { return v.ResourceIds }
+
+// __createUserInput is used internally by genqlient
+type __createUserInput struct {
+	Email            string   `json:"email"`
+	FirstName        string   `json:"firstName,omitempty"`
+	LastName         string   `json:"lastName,omitempty"`
+	Role             UserRole `json:"role,omitempty"`
+	ShouldSendInvite bool     `json:"shouldSendInvite"`
+}
+
+// GetEmail returns __createUserInput.Email, and is useful for accessing the field via an interface.
+func (v *__createUserInput) GetEmail() string { return v.Email }
+
+// GetFirstName returns __createUserInput.FirstName, and is useful for accessing the field via an interface.
+func (v *__createUserInput) GetFirstName() string { return v.FirstName }
+
+// GetLastName returns __createUserInput.LastName, and is useful for accessing the field via an interface.
+func (v *__createUserInput) GetLastName() string { return v.LastName }
+
+// GetRole returns __createUserInput.Role, and is useful for accessing the field via an interface.
+func (v *__createUserInput) GetRole() UserRole { return v.Role }
+
+// GetShouldSendInvite returns __createUserInput.ShouldSendInvite, and is useful for accessing the field via an interface.
+func (v *__createUserInput) GetShouldSendInvite() bool { return v.ShouldSendInvite }
+
+// __deleteGroupInput is used internally by genqlient
+type __deleteGroupInput struct {
+	Id string `json:"id"`
+}
+
+// GetId returns __deleteGroupInput.Id, and is useful for accessing the field via an interface.
+func (v *__deleteGroupInput) GetId() string { return v.Id }
+
+// __deleteUserInput is used internally by genqlient
+type __deleteUserInput struct {
+	Id string `json:"id"`
+}
+
+// GetId returns __deleteUserInput.Id, and is useful for accessing the field via an interface.
+func (v *__deleteUserInput) GetId() string { return v.Id }
+
+// __getGroupDetailsInput is used internally by genqlient
+type __getGroupDetailsInput struct {
+	GroupID string `json:"groupID"`
+}
+
+// GetGroupID returns __getGroupDetailsInput.GroupID, and is useful for accessing the field via an interface.
+func (v *__getGroupDetailsInput) GetGroupID() string { return v.GroupID }
+
+// __getGroupMembersInput is used internally by genqlient
+type __getGroupMembersInput struct {
+	GroupID string `json:"groupID"`
+	After   string `json:"after,omitempty"`
+	First   int    `json:"first,omitempty"`
+}
+
+// GetGroupID returns __getGroupMembersInput.GroupID, and is useful for accessing the field via an interface.
+func (v *__getGroupMembersInput) GetGroupID() string { return v.GroupID }
+
+// GetAfter returns __getGroupMembersInput.After, and is useful for accessing the field via an interface.
+func (v *__getGroupMembersInput) GetAfter() string { return v.After }
+
+// GetFirst returns __getGroupMembersInput.First, and is useful for accessing the field via an interface.
+func (v *__getGroupMembersInput) GetFirst() int { return v.First }
+
+// __getGroupsInput is used internally by genqlient
+type __getGroupsInput struct {
+	After string `json:"after,omitempty"`
+	First int    `json:"first,omitempty"`
+}
+
+// GetAfter returns __getGroupsInput.After, and is useful for accessing the field via an interface.
+func (v *__getGroupsInput) GetAfter() string { return v.After }
+
+// GetFirst returns __getGroupsInput.First, and is useful for accessing the field via an interface.
+func (v *__getGroupsInput) GetFirst() int { return v.First }
+
+// __getGroupsWithMembersInput is used internally by genqlient
+type __getGroupsWithMembersInput struct {
+	After        string `json:"after,omitempty"`
+	First        int    `json:"first,omitempty"`
+	MembersFirst int    `json:"membersFirst,omitempty"`
+}
+
+// GetAfter returns __getGroupsWithMembersInput.After, and is useful for accessing the field via an interface.
+func (v *__getGroupsWithMembersInput) GetAfter() string { return v.After }
+
+// GetFirst returns __getGroupsWithMembersInput.First, and is useful for accessing the field via an interface.
+func (v *__getGroupsWithMembersInput) GetFirst() int { return v.First }
+
+// GetMembersFirst returns __getGroupsWithMembersInput.MembersFirst, and is useful for accessing the field via an interface.
+func (v *__getGroupsWithMembersInput) GetMembersFirst() int { return v.MembersFirst }
+
+// __getUserDetailsInput is used internally by genqlient
+type __getUserDetailsInput struct {
+	UserID string `json:"userID"`
+}
+
+// GetUserID returns __getUserDetailsInput.UserID, and is useful for accessing the field via an interface.
+func (v *__getUserDetailsInput) GetUserID() string { return v.UserID }
+
+// __getUserGroupsInput is used internally by genqlient
+type __getUserGroupsInput struct {
+	UserID string `json:"userID"`
+	After  string `json:"after,omitempty"`
+	First  int    `json:"first,omitempty"`
+}
+
+// GetUserID returns __getUserGroupsInput.UserID, and is useful for accessing the field via an interface.
+func (v *__getUserGroupsInput) GetUserID() string { return v.UserID }
+
+// GetAfter returns __getUserGroupsInput.After, and is useful for accessing the field via an interface.
+func (v *__getUserGroupsInput) GetAfter() string { return v.After }
+
+// GetFirst returns __getUserGroupsInput.First, and is useful for accessing the field via an interface.
+func (v *__getUserGroupsInput) GetFirst() int { return v.First }
+
+// __getUsersInput is used internally by genqlient
+type __getUsersInput struct {
+	After string `json:"after,omitempty"`
+	First int    `json:"first,omitempty"`
+}
+
+// GetAfter returns __getUsersInput.After, and is useful for accessing the field via an interface.
+func (v *__getUsersInput) GetAfter() string { return v.After }
+
+// GetFirst returns __getUsersInput.First, and is useful for accessing the field via an interface.
+func (v *__getUsersInput) GetFirst() int { return v.First }
+
+// __renameGroupInput is used internally by genqlient
+type __renameGroupInput struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// GetId returns __renameGroupInput.Id, and is useful for accessing the field via an interface.
+func (v *__renameGroupInput) GetId() string { return v.Id }
+
+// GetName returns __renameGroupInput.Name, and is useful for accessing the field via an interface.
+func (v *__renameGroupInput) GetName() string { return v.Name }
+
+// __updateGroupMembersInput is used internally by genqlient
+type __updateGroupMembersInput struct {
+	Id             string   `json:"id"`
+	AddedUserIds   []string `json:"addedUserIds,omitempty"`
+	RemovedUserIds []string `json:"removedUserIds,omitempty"`
+}
+
+// GetId returns __updateGroupMembersInput.Id, and is useful for accessing the field via an interface.
+func (v *__updateGroupMembersInput) GetId() string { return v.Id }
+
+// GetAddedUserIds returns __updateGroupMembersInput.AddedUserIds, and is useful for accessing the field via an interface.
+func (v *__updateGroupMembersInput) GetAddedUserIds() []string { return v.AddedUserIds }
+
+// GetRemovedUserIds returns __updateGroupMembersInput.RemovedUserIds, and is useful for accessing the field via an interface.
+func (v *__updateGroupMembersInput) GetRemovedUserIds() []string { return v.RemovedUserIds }
+
+// __updateUserStateInput is used internally by genqlient
+type __updateUserStateInput struct {
+	Id    string               `json:"id"`
+	State UserStateUpdateInput `json:"state"`
+}
+
+// GetId returns __updateUserStateInput.Id, and is useful for accessing the field via an interface.
+func (v *__updateUserStateInput) GetId() string { return v.Id }
+
+// GetState returns __updateUserStateInput.State, and is useful for accessing the field via an interface.
+func (v *__updateUserStateInput) GetState() UserStateUpdateInput { return v.State }
+
+// createGroupGroupCreateGroupCreatePayload includes the requested fields of the GraphQL type GroupCreatePayload.
+type createGroupGroupCreateGroupCreatePayload struct {
+	Ok     bool                                                 `json:"ok"`
+	Error  *string                                              `json:"error"`
+	Entity *createGroupGroupCreateGroupCreatePayloadEntityGroup `json:"entity"`
+}
+
+// GetOk returns createGroupGroupCreateGroupCreatePayload.Ok, and is useful for accessing the field via an interface.
+func (v *createGroupGroupCreateGroupCreatePayload) GetOk() bool { return v.Ok }
+
+// GetError returns createGroupGroupCreateGroupCreatePayload.Error, and is useful for accessing the field via an interface.
+func (v *createGroupGroupCreateGroupCreatePayload) GetError() *string { return v.Error }
+
+// GetEntity returns createGroupGroupCreateGroupCreatePayload.Entity, and is useful for accessing the field via an interface.
+func (v *createGroupGroupCreateGroupCreatePayload) GetEntity() *createGroupGroupCreateGroupCreatePayloadEntityGroup {
+	return v.Entity
+}
+
+// createGroupGroupCreateGroupCreatePayloadEntityGroup includes the requested fields of the GraphQL type Group.
+type createGroupGroupCreateGroupCreatePayloadEntityGroup struct {
+	Id       string    `json:"id"`
+	Name     string    `json:"name"`
+	IsActive bool      `json:"isActive"`
+	Type     GroupType `json:"type"`
+}
+
+// GetId returns createGroupGroupCreateGroupCreatePayloadEntityGroup.Id, and is useful for accessing the field via an interface.
+func (v *createGroupGroupCreateGroupCreatePayloadEntityGroup) GetId() string { return v.Id }
+
+// GetName returns createGroupGroupCreateGroupCreatePayloadEntityGroup.Name, and is useful for accessing the field via an interface.
+func (v *createGroupGroupCreateGroupCreatePayloadEntityGroup) GetName() string { return v.Name }
+
+// GetIsActive returns createGroupGroupCreateGroupCreatePayloadEntityGroup.IsActive, and is useful for accessing the field via an interface.
+func (v *createGroupGroupCreateGroupCreatePayloadEntityGroup) GetIsActive() bool { return v.IsActive }
+
+// GetType returns createGroupGroupCreateGroupCreatePayloadEntityGroup.Type, and is useful for accessing the field via an interface.
+func (v *createGroupGroupCreateGroupCreatePayloadEntityGroup) GetType() GroupType { return v.Type }
+
+// createGroupResponse is returned by createGroup on success.
+type createGroupResponse struct {
+	GroupCreate createGroupGroupCreateGroupCreatePayload `json:"groupCreate"`
+}
+
+// GetGroupCreate returns createGroupResponse.GroupCreate, and is useful for accessing the field via an interface.
+func (v *createGroupResponse) GetGroupCreate() createGroupGroupCreateGroupCreatePayload {
+	return v.GroupCreate
+}
+
+// createUserResponse is returned by createUser on success.
+type createUserResponse struct {
+	UserCreate createUserUserCreateUserCreatePayload `json:"userCreate"`
+}
+
+// GetUserCreate returns createUserResponse.UserCreate, and is useful for accessing the field via an interface.
+func (v *createUserResponse) GetUserCreate() createUserUserCreateUserCreatePayload {
+	return v.UserCreate
+}
+
+// createUserUserCreateUserCreatePayload includes the requested fields of the GraphQL type UserCreatePayload.
+type createUserUserCreateUserCreatePayload struct {
+	Ok     bool                                             `json:"ok"`
+	Error  *string                                          `json:"error"`
+	Entity *createUserUserCreateUserCreatePayloadEntityUser `json:"entity"`
+}
+
+// GetOk returns createUserUserCreateUserCreatePayload.Ok, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayload) GetOk() bool { return v.Ok }
+
+// GetError returns createUserUserCreateUserCreatePayload.Error, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayload) GetError() *string { return v.Error }
+
+// GetEntity returns createUserUserCreateUserCreatePayload.Entity, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayload) GetEntity() *createUserUserCreateUserCreatePayloadEntityUser {
+	return v.Entity
+}
+
+// createUserUserCreateUserCreatePayloadEntityUser includes the requested fields of the GraphQL type User.
+type createUserUserCreateUserCreatePayloadEntityUser struct {
+	Id        string         `json:"id"`
+	FirstName string         `json:"firstName"`
+	LastName  string         `json:"lastName"`
+	Email     string         `json:"email"`
+	IsAdmin   bool           `json:"isAdmin"`
+	Role      UserRole       `json:"role"`
+	State     UserStateValue `json:"state"`
+	Type      UserType       `json:"type"`
+	CreatedAt string         `json:"createdAt"`
+	UpdatedAt string         `json:"updatedAt"`
+}
+
+// GetId returns createUserUserCreateUserCreatePayloadEntityUser.Id, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayloadEntityUser) GetId() string { return v.Id }
+
+// GetFirstName returns createUserUserCreateUserCreatePayloadEntityUser.FirstName, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayloadEntityUser) GetFirstName() string { return v.FirstName }
+
+// GetLastName returns createUserUserCreateUserCreatePayloadEntityUser.LastName, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayloadEntityUser) GetLastName() string { return v.LastName }
+
+// GetEmail returns createUserUserCreateUserCreatePayloadEntityUser.Email, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayloadEntityUser) GetEmail() string { return v.Email }
+
+// GetIsAdmin returns createUserUserCreateUserCreatePayloadEntityUser.IsAdmin, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayloadEntityUser) GetIsAdmin() bool { return v.IsAdmin }
+
+// GetRole returns createUserUserCreateUserCreatePayloadEntityUser.Role, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayloadEntityUser) GetRole() UserRole { return v.Role }
+
+// GetState returns createUserUserCreateUserCreatePayloadEntityUser.State, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayloadEntityUser) GetState() UserStateValue { return v.State }
+
+// GetType returns createUserUserCreateUserCreatePayloadEntityUser.Type, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayloadEntityUser) GetType() UserType { return v.Type }
+
+// GetCreatedAt returns createUserUserCreateUserCreatePayloadEntityUser.CreatedAt, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayloadEntityUser) GetCreatedAt() string { return v.CreatedAt }
+
+// GetUpdatedAt returns createUserUserCreateUserCreatePayloadEntityUser.UpdatedAt, and is useful for accessing the field via an interface.
+func (v *createUserUserCreateUserCreatePayloadEntityUser) GetUpdatedAt() string { return v.UpdatedAt }
+
+// deleteGroupGroupDeleteGroupDeletePayload includes the requested fields of the GraphQL type GroupDeletePayload.
+type deleteGroupGroupDeleteGroupDeletePayload struct {
+	Ok    bool    `json:"ok"`
+	Error *string `json:"error"`
+}
+
+// GetOk returns deleteGroupGroupDeleteGroupDeletePayload.Ok, and is useful for accessing the field via an interface.
+func (v *deleteGroupGroupDeleteGroupDeletePayload) GetOk() bool { return v.Ok }
+
+// GetError returns deleteGroupGroupDeleteGroupDeletePayload.Error, and is useful for accessing the field via an interface.
+func (v *deleteGroupGroupDeleteGroupDeletePayload) GetError() *string { return v.Error }
+
+// deleteGroupResponse is returned by deleteGroup on success.
+type deleteGroupResponse struct {
+	GroupDelete deleteGroupGroupDeleteGroupDeletePayload `json:"groupDelete"`
+}
+
+// GetGroupDelete returns deleteGroupResponse.GroupDelete, and is useful for accessing the field via an interface.
+func (v *deleteGroupResponse) GetGroupDelete() deleteGroupGroupDeleteGroupDeletePayload {
+	return v.GroupDelete
+}
+
+// deleteUserResponse is returned by deleteUser on success.
+type deleteUserResponse struct {
+	UserDelete deleteUserUserDeleteUserDeletePayload `json:"userDelete"`
+}
+
+// GetUserDelete returns deleteUserResponse.UserDelete, and is useful for accessing the field via an interface.
+func (v *deleteUserResponse) GetUserDelete() deleteUserUserDeleteUserDeletePayload {
+	return v.UserDelete
+}
+
+// deleteUserUserDeleteUserDeletePayload includes the requested fields of the GraphQL type UserDeletePayload.
+type deleteUserUserDeleteUserDeletePayload struct {
+	Ok    bool    `json:"ok"`
+	Error *string `json:"error"`
+}
+
+// GetOk returns deleteUserUserDeleteUserDeletePayload.Ok, and is useful for accessing the field via an interface.
+func (v *deleteUserUserDeleteUserDeletePayload) GetOk() bool { return v.Ok }
+
+// GetError returns deleteUserUserDeleteUserDeletePayload.Error, and is useful for accessing the field via an interface.
+func (v *deleteUserUserDeleteUserDeletePayload) GetError() *string { return v.Error }
+
+// getGroupDetailsGroup includes the requested fields of the GraphQL type Group.
+type getGroupDetailsGroup struct {
+	Id       string    `json:"id"`
+	Name     string    `json:"name"`
+	IsActive bool      `json:"isActive"`
+	Type     GroupType `json:"type"`
+}
+
+// GetId returns getGroupDetailsGroup.Id, and is useful for accessing the field via an interface.
+func (v *getGroupDetailsGroup) GetId() string { return v.Id }
+
+// GetName returns getGroupDetailsGroup.Name, and is useful for accessing the field via an interface.
+func (v *getGroupDetailsGroup) GetName() string { return v.Name }
+
+// GetIsActive returns getGroupDetailsGroup.IsActive, and is useful for accessing the field via an interface.
+func (v *getGroupDetailsGroup) GetIsActive() bool { return v.IsActive }
+
+// GetType returns getGroupDetailsGroup.Type, and is useful for accessing the field via an interface.
+func (v *getGroupDetailsGroup) GetType() GroupType { return v.Type }
+
+// getGroupDetailsResponse is returned by getGroupDetails on success.
+type getGroupDetailsResponse struct {
+	Group *getGroupDetailsGroup `json:"group"`
+}
+
+// GetGroup returns getGroupDetailsResponse.Group, and is useful for accessing the field via an interface.
+func (v *getGroupDetailsResponse) GetGroup() *getGroupDetailsGroup { return v.Group }
+
+// getGroupMembersGroup includes the requested fields of the GraphQL type Group.
+type getGroupMembersGroup struct {
+	Id        string                                  `json:"id"`
+	CreatedAt string                                  `json:"createdAt"`
+	UpdatedAt string                                  `json:"updatedAt"`
+	Users     getGroupMembersGroupUsersUserConnection `json:"users"`
+}
+
+// GetId returns getGroupMembersGroup.Id, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroup) GetId() string { return v.Id }
+
+// GetCreatedAt returns getGroupMembersGroup.CreatedAt, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroup) GetCreatedAt() string { return v.CreatedAt }
+
+// GetUpdatedAt returns getGroupMembersGroup.UpdatedAt, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroup) GetUpdatedAt() string { return v.UpdatedAt }
+
+// GetUsers returns getGroupMembersGroup.Users, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroup) GetUsers() getGroupMembersGroupUsersUserConnection { return v.Users }
+
+// getGroupMembersGroupUsersUserConnection includes the requested fields of the GraphQL type UserConnection.
+type getGroupMembersGroupUsersUserConnection struct {
+	Edges    []getGroupMembersGroupUsersUserConnectionEdgesUserEdge `json:"edges"`
+	PageInfo getGroupMembersGroupUsersUserConnectionPageInfo        `json:"pageInfo"`
+}
+
+// GetEdges returns getGroupMembersGroupUsersUserConnection.Edges, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroupUsersUserConnection) GetEdges() []getGroupMembersGroupUsersUserConnectionEdgesUserEdge {
+	return v.Edges
+}
+
+// GetPageInfo returns getGroupMembersGroupUsersUserConnection.PageInfo, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroupUsersUserConnection) GetPageInfo() getGroupMembersGroupUsersUserConnectionPageInfo {
+	return v.PageInfo
+}
+
+// getGroupMembersGroupUsersUserConnectionEdgesUserEdge includes the requested fields of the GraphQL type UserEdge.
+type getGroupMembersGroupUsersUserConnectionEdgesUserEdge struct {
+	Node getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser `json:"node"`
+}
+
+// GetNode returns getGroupMembersGroupUsersUserConnectionEdgesUserEdge.Node, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroupUsersUserConnectionEdgesUserEdge) GetNode() getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser {
+	return v.Node
+}
+
+// getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser includes the requested fields of the GraphQL type User.
+type getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser struct {
+	Id        string `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+// GetId returns getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser.Id, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser) GetId() string { return v.Id }
+
+// GetEmail returns getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser.Email, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser) GetEmail() string {
+	return v.Email
+}
+
+// GetFirstName returns getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser.FirstName, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser) GetFirstName() string {
+	return v.FirstName
+}
+
+// GetLastName returns getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser.LastName, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroupUsersUserConnectionEdgesUserEdgeNodeUser) GetLastName() string {
+	return v.LastName
+}
+
+// getGroupMembersGroupUsersUserConnectionPageInfo includes the requested fields of the GraphQL type PageInfo.
+type getGroupMembersGroupUsersUserConnectionPageInfo struct {
+	EndCursor   string `json:"endCursor"`
+	HasNextPage bool   `json:"hasNextPage"`
+}
+
+// GetEndCursor returns getGroupMembersGroupUsersUserConnectionPageInfo.EndCursor, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroupUsersUserConnectionPageInfo) GetEndCursor() string { return v.EndCursor }
+
+// GetHasNextPage returns getGroupMembersGroupUsersUserConnectionPageInfo.HasNextPage, and is useful for accessing the field via an interface.
+func (v *getGroupMembersGroupUsersUserConnectionPageInfo) GetHasNextPage() bool { return v.HasNextPage }
+
+// getGroupMembersResponse is returned by getGroupMembers on success.
+type getGroupMembersResponse struct {
+	Group getGroupMembersGroup `json:"group"`
+}
+
+// GetGroup returns getGroupMembersResponse.Group, and is useful for accessing the field via an interface.
+func (v *getGroupMembersResponse) GetGroup() getGroupMembersGroup { return v.Group }
+
+// getGroupsGroupsGroupConnection includes the requested fields of the GraphQL type GroupConnection.
+type getGroupsGroupsGroupConnection struct {
+	Edges    []getGroupsGroupsGroupConnectionEdgesGroupEdge `json:"edges"`
+	PageInfo getGroupsGroupsGroupConnectionPageInfo         `json:"pageInfo"`
+}
+
+// GetEdges returns getGroupsGroupsGroupConnection.Edges, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnection) GetEdges() []getGroupsGroupsGroupConnectionEdgesGroupEdge {
+	return v.Edges
+}
+
+// GetPageInfo returns getGroupsGroupsGroupConnection.PageInfo, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnection) GetPageInfo() getGroupsGroupsGroupConnectionPageInfo {
+	return v.PageInfo
+}
+
+// getGroupsGroupsGroupConnectionEdgesGroupEdge includes the requested fields of the GraphQL type GroupEdge.
+type getGroupsGroupsGroupConnectionEdgesGroupEdge struct {
+	Node getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup `json:"node"`
+}
+
+// GetNode returns getGroupsGroupsGroupConnectionEdgesGroupEdge.Node, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnectionEdgesGroupEdge) GetNode() getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup {
+	return v.Node
+}
+
+// getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup includes the requested fields of the GraphQL type Group.
+type getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup struct {
+	Id        string                                                                   `json:"id"`
+	Name      string                                                                   `json:"name"`
+	IsActive  bool                                                                     `json:"isActive"`
+	Type      GroupType                                                                `json:"type"`
+	CreatedAt string                                                                   `json:"createdAt"`
+	UpdatedAt string                                                                   `json:"updatedAt"`
+	Users     getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection `json:"users"`
+}
+
+// GetId returns getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup.Id, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetId() string { return v.Id }
+
+// GetName returns getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup.Name, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetName() string { return v.Name }
+
+// GetIsActive returns getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup.IsActive, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetIsActive() bool { return v.IsActive }
+
+// GetType returns getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup.Type, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetType() GroupType { return v.Type }
+
+// GetCreatedAt returns getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup.CreatedAt, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetCreatedAt() string {
+	return v.CreatedAt
+}
+
+// GetUpdatedAt returns getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup.UpdatedAt, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetUpdatedAt() string {
+	return v.UpdatedAt
+}
+
+// GetUsers returns getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup.Users, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetUsers() getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection {
+	return v.Users
+}
+
+// getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection includes the requested fields of the GraphQL type UserConnection.
+type getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection struct {
+	TotalCount int `json:"totalCount"`
+}
+
+// GetTotalCount returns getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection.TotalCount, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection) GetTotalCount() int {
+	return v.TotalCount
+}
+
+// getGroupsGroupsGroupConnectionPageInfo includes the requested fields of the GraphQL type PageInfo.
+type getGroupsGroupsGroupConnectionPageInfo struct {
+	EndCursor   string `json:"endCursor"`
+	HasNextPage bool   `json:"hasNextPage"`
+}
+
+// GetEndCursor returns getGroupsGroupsGroupConnectionPageInfo.EndCursor, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnectionPageInfo) GetEndCursor() string { return v.EndCursor }
+
+// GetHasNextPage returns getGroupsGroupsGroupConnectionPageInfo.HasNextPage, and is useful for accessing the field via an interface.
+func (v *getGroupsGroupsGroupConnectionPageInfo) GetHasNextPage() bool { return v.HasNextPage }
+
+// getGroupsResponse is returned by getGroups on success.
+type getGroupsResponse struct {
+	Groups getGroupsGroupsGroupConnection `json:"groups"`
+}
+
+// GetGroups returns getGroupsResponse.Groups, and is useful for accessing the field via an interface.
+func (v *getGroupsResponse) GetGroups() getGroupsGroupsGroupConnection { return v.Groups }
+
+// getGroupsWithMembersGroupsGroupConnection includes the requested fields of the GraphQL type GroupConnection.
+type getGroupsWithMembersGroupsGroupConnection struct {
+	Edges    []getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdge `json:"edges"`
+	PageInfo getGroupsWithMembersGroupsGroupConnectionPageInfo         `json:"pageInfo"`
+}
+
+// GetEdges returns getGroupsWithMembersGroupsGroupConnection.Edges, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnection) GetEdges() []getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdge {
+	return v.Edges
+}
+
+// GetPageInfo returns getGroupsWithMembersGroupsGroupConnection.PageInfo, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnection) GetPageInfo() getGroupsWithMembersGroupsGroupConnectionPageInfo {
+	return v.PageInfo
+}
+
+// getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdge includes the requested fields of the GraphQL type GroupEdge.
+type getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdge struct {
+	Node getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup `json:"node"`
+}
+
+// GetNode returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdge.Node, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdge) GetNode() getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup {
+	return v.Node
+}
+
+// getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup includes the requested fields of the GraphQL type Group.
+type getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup struct {
+	Id        string                                                                              `json:"id"`
+	Name      string                                                                              `json:"name"`
+	IsActive  bool                                                                                `json:"isActive"`
+	Type      GroupType                                                                           `json:"type"`
+	CreatedAt string                                                                              `json:"createdAt"`
+	UpdatedAt string                                                                              `json:"updatedAt"`
+	Users     getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection `json:"users"`
+}
+
+// GetId returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup.Id, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetId() string {
+	return v.Id
+}
+
+// GetName returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup.Name, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetName() string {
+	return v.Name
+}
+
+// GetIsActive returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup.IsActive, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetIsActive() bool {
+	return v.IsActive
+}
+
+// GetType returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup.Type, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetType() GroupType {
+	return v.Type
+}
+
+// GetCreatedAt returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup.CreatedAt, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetCreatedAt() string {
+	return v.CreatedAt
+}
+
+// GetUpdatedAt returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup.UpdatedAt, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetUpdatedAt() string {
+	return v.UpdatedAt
+}
+
+// GetUsers returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup.Users, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetUsers() getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection {
+	return v.Users
+}
+
+// getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection includes the requested fields of the GraphQL type UserConnection.
+type getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection struct {
+	TotalCount int                                                                                                `json:"totalCount"`
+	Edges      []getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdge `json:"edges"`
+	PageInfo   getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionPageInfo        `json:"pageInfo"`
+}
+
+// GetTotalCount returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection.TotalCount, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection) GetTotalCount() int {
+	return v.TotalCount
+}
+
+// GetEdges returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection.Edges, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection) GetEdges() []getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdge {
+	return v.Edges
+}
+
+// GetPageInfo returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection.PageInfo, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnection) GetPageInfo() getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionPageInfo {
+	return v.PageInfo
+}
+
+// getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdge includes the requested fields of the GraphQL type UserEdge.
+type getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdge struct {
+	Node getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdgeNodeUser `json:"node"`
+}
+
+// GetNode returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdge.Node, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdge) GetNode() getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdgeNodeUser {
+	return v.Node
+}
+
+// getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdgeNodeUser includes the requested fields of the GraphQL type User.
+type getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdgeNodeUser struct {
+	Id string `json:"id"`
+}
+
+// GetId returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdgeNodeUser.Id, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdgeNodeUser) GetId() string {
+	return v.Id
+}
+
+// getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionPageInfo includes the requested fields of the GraphQL type PageInfo.
+type getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionPageInfo struct {
+	EndCursor   string `json:"endCursor"`
+	HasNextPage bool   `json:"hasNextPage"`
+}
+
+// GetEndCursor returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionPageInfo.EndCursor, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionPageInfo) GetEndCursor() string {
+	return v.EndCursor
+}
+
+// GetHasNextPage returns getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionPageInfo.HasNextPage, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionPageInfo) GetHasNextPage() bool {
+	return v.HasNextPage
+}
+
+// getGroupsWithMembersGroupsGroupConnectionPageInfo includes the requested fields of the GraphQL type PageInfo.
+type getGroupsWithMembersGroupsGroupConnectionPageInfo struct {
+	EndCursor   string `json:"endCursor"`
+	HasNextPage bool   `json:"hasNextPage"`
+}
+
+// GetEndCursor returns getGroupsWithMembersGroupsGroupConnectionPageInfo.EndCursor, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionPageInfo) GetEndCursor() string { return v.EndCursor }
+
+// GetHasNextPage returns getGroupsWithMembersGroupsGroupConnectionPageInfo.HasNextPage, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersGroupsGroupConnectionPageInfo) GetHasNextPage() bool {
+	return v.HasNextPage
+}
+
+// getGroupsWithMembersResponse is returned by getGroupsWithMembers on success.
+type getGroupsWithMembersResponse struct {
+	Groups getGroupsWithMembersGroupsGroupConnection `json:"groups"`
+}
+
+// GetGroups returns getGroupsWithMembersResponse.Groups, and is useful for accessing the field via an interface.
+func (v *getGroupsWithMembersResponse) GetGroups() getGroupsWithMembersGroupsGroupConnection {
+	return v.Groups
+}
+
+// getUserDetailsResponse is returned by getUserDetails on success.
+type getUserDetailsResponse struct {
+	User *getUserDetailsUser `json:"user"`
+}
+
+// GetUser returns getUserDetailsResponse.User, and is useful for accessing the field via an interface.
+func (v *getUserDetailsResponse) GetUser() *getUserDetailsUser { return v.User }
+
+// getUserDetailsUser includes the requested fields of the GraphQL type User.
+type getUserDetailsUser struct {
+	Id        string         `json:"id"`
+	FirstName string         `json:"firstName"`
+	LastName  string         `json:"lastName"`
+	Email     string         `json:"email"`
+	IsAdmin   bool           `json:"isAdmin"`
+	Role      UserRole       `json:"role"`
+	State     UserStateValue `json:"state"`
+	Type      UserType       `json:"type"`
+	CreatedAt string         `json:"createdAt"`
+	UpdatedAt string         `json:"updatedAt"`
+}
+
+// GetId returns getUserDetailsUser.Id, and is useful for accessing the field via an interface.
+func (v *getUserDetailsUser) GetId() string { return v.Id }
+
+// GetFirstName returns getUserDetailsUser.FirstName, and is useful for accessing the field via an interface.
+func (v *getUserDetailsUser) GetFirstName() string { return v.FirstName }
+
+// GetLastName returns getUserDetailsUser.LastName, and is useful for accessing the field via an interface.
+func (v *getUserDetailsUser) GetLastName() string { return v.LastName }
+
+// GetEmail returns getUserDetailsUser.Email, and is useful for accessing the field via an interface.
+func (v *getUserDetailsUser) GetEmail() string { return v.Email }
+
+// GetIsAdmin returns getUserDetailsUser.IsAdmin, and is useful for accessing the field via an interface.
+func (v *getUserDetailsUser) GetIsAdmin() bool { return v.IsAdmin }
+
+// GetRole returns getUserDetailsUser.Role, and is useful for accessing the field via an interface.
+func (v *getUserDetailsUser) GetRole() UserRole { return v.Role }
+
+// GetState returns getUserDetailsUser.State, and is useful for accessing the field via an interface.
+func (v *getUserDetailsUser) GetState() UserStateValue { return v.State }
+
+// GetType returns getUserDetailsUser.Type, and is useful for accessing the field via an interface.
+func (v *getUserDetailsUser) GetType() UserType { return v.Type }
+
+// GetCreatedAt returns getUserDetailsUser.CreatedAt, and is useful for accessing the field via an interface.
+func (v *getUserDetailsUser) GetCreatedAt() string { return v.CreatedAt }
+
+// GetUpdatedAt returns getUserDetailsUser.UpdatedAt, and is useful for accessing the field via an interface.
+func (v *getUserDetailsUser) GetUpdatedAt() string { return v.UpdatedAt }
+
+// getUserGroupsResponse is returned by getUserGroups on success.
+type getUserGroupsResponse struct {
+	User *getUserGroupsUser `json:"user"`
+}
+
+// GetUser returns getUserGroupsResponse.User, and is useful for accessing the field via an interface.
+func (v *getUserGroupsResponse) GetUser() *getUserGroupsUser { return v.User }
+
+// getUserGroupsUser includes the requested fields of the GraphQL type User.
+type getUserGroupsUser struct {
+	Id     string                                 `json:"id"`
+	Groups getUserGroupsUserGroupsGroupConnection `json:"groups"`
+}
+
+// GetId returns getUserGroupsUser.Id, and is useful for accessing the field via an interface.
+func (v *getUserGroupsUser) GetId() string { return v.Id }
+
+// GetGroups returns getUserGroupsUser.Groups, and is useful for accessing the field via an interface.
+func (v *getUserGroupsUser) GetGroups() getUserGroupsUserGroupsGroupConnection { return v.Groups }
+
+// getUserGroupsUserGroupsGroupConnection includes the requested fields of the GraphQL type GroupConnection.
+type getUserGroupsUserGroupsGroupConnection struct {
+	Edges    []getUserGroupsUserGroupsGroupConnectionEdgesGroupEdge `json:"edges"`
+	PageInfo getUserGroupsUserGroupsGroupConnectionPageInfo         `json:"pageInfo"`
+}
+
+// GetEdges returns getUserGroupsUserGroupsGroupConnection.Edges, and is useful for accessing the field via an interface.
+func (v *getUserGroupsUserGroupsGroupConnection) GetEdges() []getUserGroupsUserGroupsGroupConnectionEdgesGroupEdge {
+	return v.Edges
+}
+
+// GetPageInfo returns getUserGroupsUserGroupsGroupConnection.PageInfo, and is useful for accessing the field via an interface.
+func (v *getUserGroupsUserGroupsGroupConnection) GetPageInfo() getUserGroupsUserGroupsGroupConnectionPageInfo {
+	return v.PageInfo
+}
+
+// getUserGroupsUserGroupsGroupConnectionEdgesGroupEdge includes the requested fields of the GraphQL type GroupEdge.
+type getUserGroupsUserGroupsGroupConnectionEdgesGroupEdge struct {
+	Node getUserGroupsUserGroupsGroupConnectionEdgesGroupEdgeNodeGroup `json:"node"`
+}
+
+// GetNode returns getUserGroupsUserGroupsGroupConnectionEdgesGroupEdge.Node, and is useful for accessing the field via an interface.
+func (v *getUserGroupsUserGroupsGroupConnectionEdgesGroupEdge) GetNode() getUserGroupsUserGroupsGroupConnectionEdgesGroupEdgeNodeGroup {
+	return v.Node
+}
+
+// getUserGroupsUserGroupsGroupConnectionEdgesGroupEdgeNodeGroup includes the requested fields of the GraphQL type Group.
+type getUserGroupsUserGroupsGroupConnectionEdgesGroupEdgeNodeGroup struct {
+	Id string `json:"id"`
+}
+
+// GetId returns getUserGroupsUserGroupsGroupConnectionEdgesGroupEdgeNodeGroup.Id, and is useful for accessing the field via an interface.
+func (v *getUserGroupsUserGroupsGroupConnectionEdgesGroupEdgeNodeGroup) GetId() string { return v.Id }
+
+// getUserGroupsUserGroupsGroupConnectionPageInfo includes the requested fields of the GraphQL type PageInfo.
+type getUserGroupsUserGroupsGroupConnectionPageInfo struct {
+	EndCursor   string `json:"endCursor"`
+	HasNextPage bool   `json:"hasNextPage"`
+}
+
+// GetEndCursor returns getUserGroupsUserGroupsGroupConnectionPageInfo.EndCursor, and is useful for accessing the field via an interface.
+func (v *getUserGroupsUserGroupsGroupConnectionPageInfo) GetEndCursor() string { return v.EndCursor }
+
+// GetHasNextPage returns getUserGroupsUserGroupsGroupConnectionPageInfo.HasNextPage, and is useful for accessing the field via an interface.
+func (v *getUserGroupsUserGroupsGroupConnectionPageInfo) GetHasNextPage() bool { return v.HasNextPage }
+
+// getUsersResponse is returned by getUsers on success.
+type getUsersResponse struct {
+	Users getUsersUsersUserConnection `json:"users"`
+}
+
+// GetUsers returns getUsersResponse.Users, and is useful for accessing the field via an interface.
+func (v *getUsersResponse) GetUsers() getUsersUsersUserConnection { return v.Users }
+
+// getUsersUsersUserConnection includes the requested fields of the GraphQL type UserConnection.
+type getUsersUsersUserConnection struct {
+	Edges    []getUsersUsersUserConnectionEdgesUserEdge `json:"edges"`
+	PageInfo getUsersUsersUserConnectionPageInfo        `json:"pageInfo"`
+}
+
+// GetEdges returns getUsersUsersUserConnection.Edges, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnection) GetEdges() []getUsersUsersUserConnectionEdgesUserEdge {
+	return v.Edges
+}
+
+// GetPageInfo returns getUsersUsersUserConnection.PageInfo, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnection) GetPageInfo() getUsersUsersUserConnectionPageInfo {
+	return v.PageInfo
+}
+
+// getUsersUsersUserConnectionEdgesUserEdge includes the requested fields of the GraphQL type UserEdge.
+type getUsersUsersUserConnectionEdgesUserEdge struct {
+	Node getUsersUsersUserConnectionEdgesUserEdgeNodeUser `json:"node"`
+}
+
+// GetNode returns getUsersUsersUserConnectionEdgesUserEdge.Node, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionEdgesUserEdge) GetNode() getUsersUsersUserConnectionEdgesUserEdgeNodeUser {
+	return v.Node
+}
+
+// getUsersUsersUserConnectionEdgesUserEdgeNodeUser includes the requested fields of the GraphQL type User.
+type getUsersUsersUserConnectionEdgesUserEdgeNodeUser struct {
+	Id        string         `json:"id"`
+	FirstName string         `json:"firstName"`
+	LastName  string         `json:"lastName"`
+	Email     string         `json:"email"`
+	CreatedAt string         `json:"createdAt"`
+	UpdatedAt string         `json:"updatedAt"`
+	IsAdmin   bool           `json:"isAdmin"`
+	Role      UserRole       `json:"role"`
+	State     UserStateValue `json:"state"`
+	Type      UserType       `json:"type"`
+}
+
+// GetId returns getUsersUsersUserConnectionEdgesUserEdgeNodeUser.Id, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionEdgesUserEdgeNodeUser) GetId() string { return v.Id }
+
+// GetFirstName returns getUsersUsersUserConnectionEdgesUserEdgeNodeUser.FirstName, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionEdgesUserEdgeNodeUser) GetFirstName() string { return v.FirstName }
+
+// GetLastName returns getUsersUsersUserConnectionEdgesUserEdgeNodeUser.LastName, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionEdgesUserEdgeNodeUser) GetLastName() string { return v.LastName }
+
+// GetEmail returns getUsersUsersUserConnectionEdgesUserEdgeNodeUser.Email, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionEdgesUserEdgeNodeUser) GetEmail() string { return v.Email }
+
+// GetCreatedAt returns getUsersUsersUserConnectionEdgesUserEdgeNodeUser.CreatedAt, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionEdgesUserEdgeNodeUser) GetCreatedAt() string { return v.CreatedAt }
+
+// GetUpdatedAt returns getUsersUsersUserConnectionEdgesUserEdgeNodeUser.UpdatedAt, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionEdgesUserEdgeNodeUser) GetUpdatedAt() string { return v.UpdatedAt }
+
+// GetIsAdmin returns getUsersUsersUserConnectionEdgesUserEdgeNodeUser.IsAdmin, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionEdgesUserEdgeNodeUser) GetIsAdmin() bool { return v.IsAdmin }
+
+// GetRole returns getUsersUsersUserConnectionEdgesUserEdgeNodeUser.Role, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionEdgesUserEdgeNodeUser) GetRole() UserRole { return v.Role }
+
+// GetState returns getUsersUsersUserConnectionEdgesUserEdgeNodeUser.State, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionEdgesUserEdgeNodeUser) GetState() UserStateValue { return v.State }
+
+// GetType returns getUsersUsersUserConnectionEdgesUserEdgeNodeUser.Type, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionEdgesUserEdgeNodeUser) GetType() UserType { return v.Type }
+
+// getUsersUsersUserConnectionPageInfo includes the requested fields of the GraphQL type PageInfo.
+type getUsersUsersUserConnectionPageInfo struct {
+	EndCursor   string `json:"endCursor"`
+	HasNextPage bool   `json:"hasNextPage"`
+}
+
+// GetEndCursor returns getUsersUsersUserConnectionPageInfo.EndCursor, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionPageInfo) GetEndCursor() string { return v.EndCursor }
+
+// GetHasNextPage returns getUsersUsersUserConnectionPageInfo.HasNextPage, and is useful for accessing the field via an interface.
+func (v *getUsersUsersUserConnectionPageInfo) GetHasNextPage() bool { return v.HasNextPage }
+
+// renameGroupGroupUpdateGroupUpdatePayload includes the requested fields of the GraphQL type GroupUpdatePayload.
+type renameGroupGroupUpdateGroupUpdatePayload struct {
+	Ok    bool    `json:"ok"`
+	Error *string `json:"error"`
+}
+
+// GetOk returns renameGroupGroupUpdateGroupUpdatePayload.Ok, and is useful for accessing the field via an interface.
+func (v *renameGroupGroupUpdateGroupUpdatePayload) GetOk() bool { return v.Ok }
+
+// GetError returns renameGroupGroupUpdateGroupUpdatePayload.Error, and is useful for accessing the field via an interface.
+func (v *renameGroupGroupUpdateGroupUpdatePayload) GetError() *string { return v.Error }
+
+// renameGroupResponse is returned by renameGroup on success.
+type renameGroupResponse struct {
+	GroupUpdate renameGroupGroupUpdateGroupUpdatePayload `json:"groupUpdate"`
+}
+
+// GetGroupUpdate returns renameGroupResponse.GroupUpdate, and is useful for accessing the field via an interface.
+func (v *renameGroupResponse) GetGroupUpdate() renameGroupGroupUpdateGroupUpdatePayload {
+	return v.GroupUpdate
+}
+
+// updateGroupMembersGroupUpdateGroupUpdatePayload includes the requested fields of the GraphQL type GroupUpdatePayload.
+type updateGroupMembersGroupUpdateGroupUpdatePayload struct {
+	Ok    bool    `json:"ok"`
+	Error *string `json:"error"`
+}
+
+// GetOk returns updateGroupMembersGroupUpdateGroupUpdatePayload.Ok, and is useful for accessing the field via an interface.
+func (v *updateGroupMembersGroupUpdateGroupUpdatePayload) GetOk() bool { return v.Ok }
+
+// GetError returns updateGroupMembersGroupUpdateGroupUpdatePayload.Error, and is useful for accessing the field via an interface.
+func (v *updateGroupMembersGroupUpdateGroupUpdatePayload) GetError() *string { return v.Error }
+
+// updateGroupMembersResponse is returned by updateGroupMembers on success.
+type updateGroupMembersResponse struct {
+	GroupUpdate updateGroupMembersGroupUpdateGroupUpdatePayload `json:"groupUpdate"`
+}
+
+// GetGroupUpdate returns updateGroupMembersResponse.GroupUpdate, and is useful for accessing the field via an interface.
+func (v *updateGroupMembersResponse) GetGroupUpdate() updateGroupMembersGroupUpdateGroupUpdatePayload {
+	return v.GroupUpdate
+}
+
+// updateUserStateResponse is returned by updateUserState on success.
+type updateUserStateResponse struct {
+	UserDetailsUpdate updateUserStateUserDetailsUpdateUserDetailsUpdatePayload `json:"userDetailsUpdate"`
+}
+
+// GetUserDetailsUpdate returns updateUserStateResponse.UserDetailsUpdate, and is useful for accessing the field via an interface.
+func (v *updateUserStateResponse) GetUserDetailsUpdate() updateUserStateUserDetailsUpdateUserDetailsUpdatePayload {
+	return v.UserDetailsUpdate
+}
+
+// updateUserStateUserDetailsUpdateUserDetailsUpdatePayload includes the requested fields of the GraphQL type UserDetailsUpdatePayload.
+type updateUserStateUserDetailsUpdateUserDetailsUpdatePayload struct {
+	Ok     bool                                                                `json:"ok"`
+	Error  *string                                                             `json:"error"`
+	Entity *updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser `json:"entity"`
+}
+
+// GetOk returns updateUserStateUserDetailsUpdateUserDetailsUpdatePayload.Ok, and is useful for accessing the field via an interface.
+func (v *updateUserStateUserDetailsUpdateUserDetailsUpdatePayload) GetOk() bool { return v.Ok }
+
+// GetError returns updateUserStateUserDetailsUpdateUserDetailsUpdatePayload.Error, and is useful for accessing the field via an interface.
+func (v *updateUserStateUserDetailsUpdateUserDetailsUpdatePayload) GetError() *string { return v.Error }
+
+// GetEntity returns updateUserStateUserDetailsUpdateUserDetailsUpdatePayload.Entity, and is useful for accessing the field via an interface.
+func (v *updateUserStateUserDetailsUpdateUserDetailsUpdatePayload) GetEntity() *updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser {
+	return v.Entity
+}
+
+// updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser includes the requested fields of the GraphQL type User.
+type updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser struct {
+	Id        string         `json:"id"`
+	FirstName string         `json:"firstName"`
+	LastName  string         `json:"lastName"`
+	Email     string         `json:"email"`
+	IsAdmin   bool           `json:"isAdmin"`
+	State     UserStateValue `json:"state"`
+}
+
+// GetId returns updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser.Id, and is useful for accessing the field via an interface.
+func (v *updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser) GetId() string {
+	return v.Id
+}
+
+// GetFirstName returns updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser.FirstName, and is useful for accessing the field via an interface.
+func (v *updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser) GetFirstName() string {
+	return v.FirstName
+}
+
+// GetLastName returns updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser.LastName, and is useful for accessing the field via an interface.
+func (v *updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser) GetLastName() string {
+	return v.LastName
+}
+
+// GetEmail returns updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser.Email, and is useful for accessing the field via an interface.
+func (v *updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser) GetEmail() string {
+	return v.Email
+}
+
+// GetIsAdmin returns updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser.IsAdmin, and is useful for accessing the field via an interface.
+func (v *updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser) GetIsAdmin() bool {
+	return v.IsAdmin
+}
+
+// GetState returns updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser.State, and is useful for accessing the field via an interface.
+func (v *updateUserStateUserDetailsUpdateUserDetailsUpdatePayloadEntityUser) GetState() UserStateValue {
+	return v.State
+}
+
+// The query or mutation executed by createGroup.
+const createGroup_Operation = `
+mutation createGroup ($name: String!, $userIds: [ID], $resourceIds: [ID]) {
+	groupCreate(name: $name, userIds: $userIds, resourceIds: $resourceIds) {
+		ok
+		error
+		entity {
+			id
+			name
+			isActive
+			type
+		}
+	}
+}
+`
+
+func createGroup(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	name string,
+	userIds []string,
+	resourceIds []string,
+) (*createGroupResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "createGroup",
+		Query:  createGroup_Operation,
+		Variables: &__createGroupInput{
+			Name:        name,
+			UserIds:     userIds,
+			ResourceIds: resourceIds,
+		},
+	}
+	var err_ error
+
+	var data_ createGroupResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by createUser.
+const createUser_Operation = `
+mutation createUser ($email: String!, $firstName: String, $lastName: String, $role: UserRole, $shouldSendInvite: Boolean) {
+	userCreate(email: $email, firstName: $firstName, lastName: $lastName, role: $role, shouldSendInvite: $shouldSendInvite) {
+		ok
+		error
+		entity {
+			id
+			firstName
+			lastName
+			email
+			isAdmin
+			role
+			state
+			type
+			createdAt
+			updatedAt
+		}
+	}
+}
+`
+
+func createUser(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	email string,
+	firstName string,
+	lastName string,
+	role UserRole,
+	shouldSendInvite bool,
+) (*createUserResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "createUser",
+		Query:  createUser_Operation,
+		Variables: &__createUserInput{
+			Email:            email,
+			FirstName:        firstName,
+			LastName:         lastName,
+			Role:             role,
+			ShouldSendInvite: shouldSendInvite,
+		},
+	}
+	var err_ error
+
+	var data_ createUserResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by deleteGroup.
+const deleteGroup_Operation = `
+mutation deleteGroup ($id: ID!) {
+	groupDelete(id: $id) {
+		ok
+		error
+	}
+}
+`
+
+func deleteGroup(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	id string,
+) (*deleteGroupResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "deleteGroup",
+		Query:  deleteGroup_Operation,
+		Variables: &__deleteGroupInput{
+			Id: id,
+		},
+	}
+	var err_ error
+
+	var data_ deleteGroupResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by deleteUser.
+const deleteUser_Operation = `
+mutation deleteUser ($id: ID!) {
+	userDelete(id: $id) {
+		ok
+		error
+	}
+}
+`
+
+func deleteUser(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	id string,
+) (*deleteUserResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "deleteUser",
+		Query:  deleteUser_Operation,
+		Variables: &__deleteUserInput{
+			Id: id,
+		},
+	}
+	var err_ error
+
+	var data_ deleteUserResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by getGroupDetails.
+const getGroupDetails_Operation = `
+query getGroupDetails ($groupID: ID!) {
+	group(id: $groupID) {
+		id
+		name
+		isActive
+		type
+	}
+}
+`
+
+func getGroupDetails(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	groupID string,
+) (*getGroupDetailsResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "getGroupDetails",
+		Query:  getGroupDetails_Operation,
+		Variables: &__getGroupDetailsInput{
+			GroupID: groupID,
+		},
+	}
+	var err_ error
+
+	var data_ getGroupDetailsResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by getGroupMembers.
+const getGroupMembers_Operation = `
+query getGroupMembers ($groupID: ID!, $after: String, $first: Int) {
+	group(id: $groupID) {
+		id
+		createdAt
+		updatedAt
+		users(after: $after, first: $first) {
+			edges {
+				node {
+					id
+					email
+					firstName
+					lastName
+				}
+			}
+			pageInfo {
+				endCursor
+				hasNextPage
+			}
+		}
+	}
+}
+`
+
+func getGroupMembers(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	groupID string,
+	after string,
+	first int,
+) (*getGroupMembersResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "getGroupMembers",
+		Query:  getGroupMembers_Operation,
+		Variables: &__getGroupMembersInput{
+			GroupID: groupID,
+			After:   after,
+			First:   first,
+		},
+	}
+	var err_ error
+
+	var data_ getGroupMembersResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by getGroups.
+const getGroups_Operation = `
+query getGroups ($after: String, $first: Int) {
+	groups(after: $after, first: $first) {
+		edges {
+			node {
+				id
+				name
+				isActive
+				type
+				createdAt
+				updatedAt
+				users {
+					totalCount
+				}
+			}
+		}
+		pageInfo {
+			endCursor
+			hasNextPage
+		}
+	}
+}
+`
+
+func getGroups(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	after string,
+	first int,
+) (*getGroupsResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "getGroups",
+		Query:  getGroups_Operation,
+		Variables: &__getGroupsInput{
+			After: after,
+			First: first,
+		},
+	}
+	var err_ error
+
+	var data_ getGroupsResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by getGroupsWithMembers.
+const getGroupsWithMembers_Operation = `
+query getGroupsWithMembers ($after: String, $first: Int, $membersFirst: Int) {
+	groups(after: $after, first: $first) {
+		edges {
+			node {
+				id
+				name
+				isActive
+				type
+				createdAt
+				updatedAt
+				users(first: $membersFirst) {
+					totalCount
+					edges {
+						node {
+							id
+						}
+					}
+					pageInfo {
+						endCursor
+						hasNextPage
+					}
+				}
+			}
+		}
+		pageInfo {
+			endCursor
+			hasNextPage
+		}
+	}
+}
+`
+
+func getGroupsWithMembers(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	after string,
+	first int,
+	membersFirst int,
+) (*getGroupsWithMembersResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "getGroupsWithMembers",
+		Query:  getGroupsWithMembers_Operation,
+		Variables: &__getGroupsWithMembersInput{
+			After:        after,
+			First:        first,
+			MembersFirst: membersFirst,
+		},
+	}
+	var err_ error
+
+	var data_ getGroupsWithMembersResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by getUserDetails.
+const getUserDetails_Operation = `
+query getUserDetails ($userID: ID!) {
+	user(id: $userID) {
+		id
+		firstName
+		lastName
+		email
+		isAdmin
+		role
+		state
+		type
+		createdAt
+		updatedAt
+	}
+}
+`
+
+func getUserDetails(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	userID string,
+) (*getUserDetailsResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "getUserDetails",
+		Query:  getUserDetails_Operation,
+		Variables: &__getUserDetailsInput{
+			UserID: userID,
+		},
+	}
+	var err_ error
+
+	var data_ getUserDetailsResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by getUserGroups.
+const getUserGroups_Operation = `
+query getUserGroups ($userID: ID!, $after: String, $first: Int) {
+	user(id: $userID) {
+		id
+		groups(after: $after, first: $first) {
+			edges {
+				node {
+					id
+				}
+			}
+			pageInfo {
+				endCursor
+				hasNextPage
+			}
+		}
+	}
+}
+`
+
+func getUserGroups(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	userID string,
+	after string,
+	first int,
+) (*getUserGroupsResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "getUserGroups",
+		Query:  getUserGroups_Operation,
+		Variables: &__getUserGroupsInput{
+			UserID: userID,
+			After:  after,
+			First:  first,
+		},
+	}
+	var err_ error
+
+	var data_ getUserGroupsResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by getUsers.
+const getUsers_Operation = `
+query getUsers ($after: String, $first: Int) {
+	users(after: $after, first: $first) {
+		edges {
+			node {
+				id
+				firstName
+				lastName
+				email
+				createdAt
+				updatedAt
+				isAdmin
+				role
+				state
+				type
+			}
+		}
+		pageInfo {
+			endCursor
+			hasNextPage
+		}
+	}
+}
+`
+
+func getUsers(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	after string,
+	first int,
+) (*getUsersResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "getUsers",
+		Query:  getUsers_Operation,
+		Variables: &__getUsersInput{
+			After: after,
+			First: first,
+		},
+	}
+	var err_ error
+
+	var data_ getUsersResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by renameGroup.
+const renameGroup_Operation = `
+mutation renameGroup ($id: ID!, $name: String!) {
+	groupUpdate(id: $id, name: $name) {
+		ok
+		error
+	}
+}
+`
+
+func renameGroup(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	id string,
+	name string,
+) (*renameGroupResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "renameGroup",
+		Query:  renameGroup_Operation,
+		Variables: &__renameGroupInput{
+			Id:   id,
+			Name: name,
+		},
+	}
+	var err_ error
+
+	var data_ renameGroupResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by updateGroupMembers.
+const updateGroupMembers_Operation = `
+mutation updateGroupMembers ($id: ID!, $addedUserIds: [ID], $removedUserIds: [ID]) {
+	groupUpdate(id: $id, addedUserIds: $addedUserIds, removedUserIds: $removedUserIds) {
+		ok
+		error
+	}
+}
+`
+
+func updateGroupMembers(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	id string,
+	addedUserIds []string,
+	removedUserIds []string,
+) (*updateGroupMembersResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "updateGroupMembers",
+		Query:  updateGroupMembers_Operation,
+		Variables: &__updateGroupMembersInput{
+			Id:             id,
+			AddedUserIds:   addedUserIds,
+			RemovedUserIds: removedUserIds,
+		},
+	}
+	var err_ error
+
+	var data_ updateGroupMembersResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
+
+// The query or mutation executed by updateUserState.
+const updateUserState_Operation = `
+mutation updateUserState ($id: ID!, $state: UserStateUpdateInput!) {
+	userDetailsUpdate(id: $id, state: $state) {
+		ok
+		error
+		entity {
+			id
+			firstName
+			lastName
+			email
+			isAdmin
+			state
+		}
+	}
+}
+`
+
+func updateUserState(
+	ctx_ context.Context,
+	client_ graphql.Client,
+	id string,
+	state UserStateUpdateInput,
+) (*updateUserStateResponse, error) {
+	req_ := &graphql.Request{
+		OpName: "updateUserState",
+		Query:  updateUserState_Operation,
+		Variables: &__updateUserStateInput{
+			Id:    id,
+			State: state,
+		},
+	}
+	var err_ error
+
+	var data_ updateUserStateResponse
+	resp_ := &graphql.Response{Data: &data_}
+
+	err_ = client_.MakeRequest(
+		ctx_,
+		req_,
+		resp_,
+	)
+
+	return &data_, err_
+}
@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// ConnectionType names a paginated Twingate list. Each one has its own page size, since a page of group members costs
+// Twingate much less than a page of groups with their members.
+type ConnectionType string
+
+const (
+	ConnectionUsers        ConnectionType = "user"
+	ConnectionGroups       ConnectionType = "group"
+	ConnectionGroupMembers ConnectionType = "group-member"
+)
+
+// ConnectionTypes are all connection types, in the order they are documented.
+var ConnectionTypes = []ConnectionType{ConnectionUsers, ConnectionGroups, ConnectionGroupMembers}
+
+// PageSizePolicy controls how page sizes adapt. A page that times out, or takes longer than Timeout, is fetched again
+// with half the size, and a page slower than SlowLatency halves the size of the next one. After GrowAfter pages
+// faster than FastLatency in a row the size grows by half. Sizes stay between Min and Max. Pages of Min nodes are
+// never cut short by Timeout.
+type PageSizePolicy struct {
+	Initial     uint32
+	Min         uint32
+	Max         uint32
+	SlowLatency time.Duration
+	FastLatency time.Duration
+	GrowAfter   int
+	Timeout     time.Duration
+}
+
+// DefaultPageSizePolicy is used unless WithPageSizePolicy is given.
+var DefaultPageSizePolicy = PageSizePolicy{
+	Initial:     100,
+	Min:         10,
+	Max:         500,
+	SlowLatency: 5 * time.Second,
+	FastLatency: time.Second,
+	GrowAfter:   3,
+	Timeout:     30 * time.Second,
+}
+
+// WithPageSizePolicy replaces DefaultPageSizePolicy.
+func WithPageSizePolicy(policy PageSizePolicy) Option {
+	return func(c *ConnectorClient) {
+		c.pageSizes.policy = policy
+	}
+}
+
+// WithPageSize fixes the page size of a connection type, which is then never adapted.
+func WithPageSize(conn ConnectionType, size uint32) Option {
+	return func(c *ConnectorClient) {
+		c.pageSizes.fixed[conn] = size
+	}
+}
+
+// ParseConnectionType returns the connection type named s.
+func ParseConnectionType(s string) (ConnectionType, error) {
+	for _, conn := range ConnectionTypes {
+		if string(conn) == s {
+			return conn, nil
+		}
+	}
+	return "", fmt.Errorf("twingate-client: unknown connection type %q, expected one of %v", s, ConnectionTypes)
+}
+
+// pageSizer tracks the page size of every connection type.
+type pageSizer struct {
+	policy PageSizePolicy
+
+	mu    sync.Mutex
+	fixed map[ConnectionType]uint32
+	sizes map[ConnectionType]uint32
+	fast  map[ConnectionType]int
+}
+
+func newPageSizer() *pageSizer {
+	return &pageSizer{
+		policy: DefaultPageSizePolicy,
+		fixed:  make(map[ConnectionType]uint32),
+		sizes:  make(map[ConnectionType]uint32),
+		fast:   make(map[ConnectionType]int),
+	}
+}
+
+func (p *pageSizer) clamp(size uint32) uint32 {
+	if size < p.policy.Min {
+		return p.policy.Min
+	}
+	if p.policy.Max > 0 && size > p.policy.Max {
+		return p.policy.Max
+	}
+	return size
+}
+
+// size returns the page size to use for conn. A resumed size, taken from a page token, is used so that a resumed sync
+// continues with the size it left off with, within the bounds of the policy since the token may come from another
+// version or configuration.
+func (p *pageSizer) size(conn ConnectionType, resumed uint32) uint32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if size, ok := p.fixed[conn]; ok {
+		return size
+	}
+	if resumed != 0 {
+		resumed = p.clamp(resumed)
+		if _, ok := p.sizes[conn]; !ok {
+			p.sizes[conn] = resumed
+		}
+		return resumed
+	}
+	return p.current(conn)
+}
+
+// current returns the adapted page size of conn. p.mu must be held.
+func (p *pageSizer) current(conn ConnectionType) uint32 {
+	if size, ok := p.sizes[conn]; ok {
+		return size
+	}
+	return p.clamp(p.policy.Initial)
+}
+
+// canShrink reports whether shrink would return a smaller size.
+func (p *pageSizer) canShrink(conn ConnectionType, size uint32) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.fixed[conn]
+	return !ok && size > p.policy.Min
+}
+
+// shrink halves the page size of conn after a page of size failed or was slow, and returns the new size. It returns
+// zero when the size cannot shrink any further.
+func (p *pageSizer) shrink(conn ConnectionType, size uint32) uint32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fast[conn] = 0
+	if _, ok := p.fixed[conn]; ok || size <= p.policy.Min {
+		return 0
+	}
+	next := p.clamp(size / 2)
+	if current, ok := p.sizes[conn]; !ok || next < current {
+		p.sizes[conn] = next
+	}
+	return next
+}
+
+// observe adapts the page size of conn to the latency of a page of size that succeeded.
+func (p *pageSizer) observe(ctx context.Context, conn ConnectionType, size uint32, latency time.Duration) {
+	if p.policy.SlowLatency > 0 && latency > p.policy.SlowLatency {
+		if next := p.shrink(conn, size); next != 0 {
+			ctxzap.Extract(ctx).Debug("twingate-client: shrinking page size after a slow page",
+				zap.String("connection", string(conn)),
+				zap.Uint32("page_size", next),
+				zap.Duration("latency", latency),
+			)
+		}
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.fixed[conn]; ok || latency > p.policy.FastLatency {
+		p.fast[conn] = 0
+		return
+	}
+	if size != p.current(conn) {
+		// Pages of other sizes, such as the single user fetched to check permissions, say little about the
+		// current size.
+		return
+	}
+	p.fast[conn]++
+	if p.fast[conn] < p.policy.GrowAfter {
+		return
+	}
+	p.fast[conn] = 0
+	next := p.clamp(size + size/2)
+	if next > size {
+		p.sizes[conn] = next
+	}
+}
+
+// PageSize returns the page size to request for conn. resumed is the size stored in the page token of a resumed
+// sync, or zero.
+func (c *ConnectorClient) PageSize(conn ConnectionType, resumed uint32) uint32 {
+	return c.pageSizes.size(conn, resumed)
+}
+
+type shrinkOnTimeoutKey struct{}
+
+// fetchPageOnce calls fetch with size. When the size can still shrink, timeouts are not retried with the same size,
+// since a smaller page is more likely to succeed, and the page is cut short after the Timeout of the policy. Such a
+// page fails with a retryableError marked as a timeout.
+func (c *ConnectorClient) fetchPageOnce(ctx context.Context, conn ConnectionType, size uint32, fetch func(ctx context.Context, first int) error) error {
+	fetchCtx := ctx
+	if c.pageSizes.canShrink(conn, size) {
+		fetchCtx = context.WithValue(ctx, shrinkOnTimeoutKey{}, true)
+		if timeout := c.pageSizes.policy.Timeout; timeout > 0 {
+			var cancel context.CancelFunc
+			fetchCtx, cancel = context.WithTimeout(fetchCtx, timeout)
+			defer cancel()
+		}
+	}
+	start := time.Now()
+	err := fetch(fetchCtx, int(size))
+	if err == nil {
+		c.pageSizes.observe(ctx, conn, size, time.Since(start))
+		return nil
+	}
+	if ctx.Err() == nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+		return &retryableError{class: failureTransient, timeout: true, err: fmt.Errorf("twingate-client: page of %d took longer than %s: %w", size, c.pageSizes.policy.Timeout, err)}
+	}
+	return err
+}
+
+// fetchPage calls fetch with the page size, and calls it again with a smaller size when the page times out or takes
+// longer than the Timeout of the policy. The page size of conn is adapted to the latency of the page.
+func (c *ConnectorClient) fetchPage(ctx context.Context, conn ConnectionType, size uint32, fetch func(ctx context.Context, first int) error) error {
+	for {
+		err := c.fetchPageOnce(ctx, conn, size, fetch)
+		if err == nil {
+			return nil
+		}
+		var re *retryableError
+		if !errors.As(err, &re) || !re.timeout {
+			return err
+		}
+		next := c.pageSizes.shrink(conn, size)
+		if next == 0 {
+			return err
+		}
+		ctxzap.Extract(ctx).Debug("twingate-client: page timed out, fetching it again with a smaller size",
+			zap.String("connection", string(conn)),
+			zap.Uint32("page_size", next),
+			zap.Error(err),
+		)
+		size = next
+	}
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+)
+
+var testPageSizePolicy = PageSizePolicy{
+	Initial:     40,
+	Min:         10,
+	Max:         80,
+	SlowLatency: time.Minute,
+	FastLatency: time.Millisecond,
+	GrowAfter:   3,
+	Timeout:     100 * time.Millisecond,
+}
+
+func TestResumedPageSizeIsClamped(t *testing.T) {
+	c, _ := newTestClient(t, testDataset(1), WithPageSizePolicy(testPageSizePolicy))
+	if size := c.PageSize(ConnectionUsers, 100000); size != testPageSizePolicy.Max {
+		t.Errorf("resumed a page size of 100000 as %d, want %d", size, testPageSizePolicy.Max)
+	}
+	if size := c.PageSize(ConnectionGroups, 1); size != testPageSizePolicy.Min {
+		t.Errorf("resumed a page size of 1 as %d, want %d", size, testPageSizePolicy.Min)
+	}
+	if size := c.PageSize(ConnectionGroupMembers, 20); size != 20 {
+		t.Errorf("resumed a page size of 20 as %d", size)
+	}
+}
+
+// slowPages delays the answer to requests for more than maxFast nodes.
+type slowPages struct {
+	next    http.Handler
+	maxFast int
+	delay   time.Duration
+	slow    atomic.Int32
+}
+
+func (s *slowPages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	var q struct {
+		Variables struct {
+			First int `json:"first"`
+		} `json:"variables"`
+	}
+	if err := json.Unmarshal(body, &q); err == nil && q.Variables.First > s.maxFast {
+		s.slow.Add(1)
+		select {
+		case <-time.After(s.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	s.next.ServeHTTP(w, r)
+}
+
+func TestSlowPageShrinks(t *testing.T) {
+	ctx := context.Background()
+	fake := twingatefake.New(testDataset(30), twingatefake.WithAPIKey(testAPIKey))
+	slow := &slowPages{next: fake, maxFast: 10, delay: time.Second}
+	server := httptest.NewServer(slow)
+	t.Cleanup(server.Close)
+	c, err := New(ctx, testAPIKey, "example",
+		WithAPIURL(server.URL+"/api/graphql/"),
+		WithRetryPolicy(RetryPolicy{}),
+		WithPageSizePolicy(testPageSizePolicy),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := c.ListUsers(ctx, "", c.PageSize(ConnectionUsers, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 40 and 20 take longer than the timeout, 10 does not.
+	if len(users.Nodes) != 10 {
+		t.Errorf("got a page of %d users, want 10", len(users.Nodes))
+	}
+	if n := slow.slow.Load(); n != 2 {
+		t.Errorf("sent %d slow pages, want 2", n)
+	}
+	if size := c.PageSize(ConnectionUsers, 0); size != 10 {
+		t.Errorf("page size is %d after the slow pages, want 10", size)
+	}
+}
+
+func TestSlowPageOfMinSizeIsNotCut(t *testing.T) {
+	ctx := context.Background()
+	fake := twingatefake.New(testDataset(30), twingatefake.WithAPIKey(testAPIKey))
+	server := httptest.NewServer(&slowPages{next: fake, maxFast: 0, delay: 200 * time.Millisecond})
+	t.Cleanup(server.Close)
+	c, err := New(ctx, testAPIKey, "example",
+		WithAPIURL(server.URL+"/api/graphql/"),
+		WithRetryPolicy(RetryPolicy{}),
+		WithPageSizePolicy(testPageSizePolicy),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every page is slow, so the size shrinks down to the minimum, which is waited for.
+	users, err := c.ListUsers(ctx, "", c.PageSize(ConnectionUsers, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users.Nodes) != 10 {
+		t.Errorf("got a page of %d users, want 10", len(users.Nodes))
+	}
+}
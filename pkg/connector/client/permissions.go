@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Permission is something the connector needs the API key to be allowed to do.
+type Permission string
+
+const (
+	PermissionReadUsers   Permission = "read_users"
+	PermissionReadGroups  Permission = "read_groups"
+	PermissionWriteGroups Permission = "write_groups"
+	PermissionWriteUsers  Permission = "write_users"
+)
+
+// PermissionReport lists the permissions the API key was found to have and to lack, and those that could not be
+// checked.
+type PermissionReport struct {
+	Granted    []Permission
+	Missing    []Permission
+	Unverified []Permission
+}
+
+// Has reports whether p was granted.
+func (r *PermissionReport) Has(p Permission) bool {
+	for _, granted := range r.Granted {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ProbePermissions checks permissions with the smallest query that needs each of them, listing a single object.
+// Probing never changes anything: write permissions can only be checked by a mutation, so they are reported as
+// unverified instead, and a missing one shows up as ErrPermissionDenied on the first change. Errors other than a
+// denied permission are returned as is.
+func (c *ConnectorClient) ProbePermissions(ctx context.Context, permissions []Permission) (*PermissionReport, error) {
+	probes := map[Permission]func(ctx context.Context) error{
+		PermissionReadUsers: func(ctx context.Context) error {
+			_, err := c.ListUsers(ctx, "", 1)
+			return err
+		},
+		PermissionReadGroups: func(ctx context.Context) error {
+			_, err := c.ListGroupsWithMembers(ctx, "", 1, 1)
+			return err
+		},
+	}
+
+	rv := &PermissionReport{}
+	for _, p := range permissions {
+		probe, ok := probes[p]
+		if !ok {
+			rv.Unverified = append(rv.Unverified, p)
+			continue
+		}
+		err := probe(ctx)
+		switch {
+		case err == nil:
+			rv.Granted = append(rv.Granted, p)
+		case errors.Is(err, ErrPermissionDenied):
+			rv.Missing = append(rv.Missing, p)
+		default:
+			return nil, fmt.Errorf("twingate-client: error checking %s permission: %w", p, err)
+		}
+	}
+	return rv, nil
+}
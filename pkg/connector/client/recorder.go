@@ -0,0 +1,347 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+const redacted = "REDACTED"
+
+// recordedHeaders are the response headers kept in recordings. Others, such as Date or request IDs, change on every
+// request and are dropped so that recordings of the same tenant can be diffed.
+var recordedHeaders = []string{"Content-Type", "Retry-After"}
+
+var operationNameRe = regexp.MustCompile(`^\s*(query|mutation)\s+(\w+)`)
+
+// cursorFields are the response fields holding pagination cursors, and cursorVariables the request variables they are
+// sent back in. Cursors are opaque and may change between two listings of the same data, so recordings replace them
+// with aliases numbered in the order they are first seen.
+var (
+	cursorFields    = map[string]bool{"endCursor": true, "startCursor": true}
+	cursorVariables = map[string]bool{"after": true, "before": true}
+)
+
+// pageSizeVariables are the request variables holding page sizes. Page sizes adapt to the latency of Twingate, so a
+// replay may ask for other sizes than the recorded sync. They are left out when matching requests to recordings,
+// and the replay serves the recorded pages whatever their size.
+var pageSizeVariables = map[string]bool{"first": true, "membersFirst": true}
+
+// mutationTimestampFields are the fields of mutation responses set to the time the mutation was applied. They are
+// recorded as mutationTimestamp.
+var mutationTimestampFields = map[string]bool{"createdAt": true, "updatedAt": true}
+
+const mutationTimestamp = "1970-01-01T00:00:00Z"
+
+// Recording is a GraphQL request and its response as saved to a record directory.
+type Recording struct {
+	Operation string          `json:"operation"`
+	Request   json.RawMessage `json:"request"`
+	Response  struct {
+		StatusCode int               `json:"statusCode"`
+		Headers    map[string]string `json:"headers,omitempty"`
+		Body       json.RawMessage   `json:"body"`
+	} `json:"response"`
+}
+
+// WithRecordDir saves every request sent to the API and its response to dir, with the API key redacted.
+func WithRecordDir(dir string) Option {
+	return func(c *ConnectorClient) {
+		c.recordDir = dir
+	}
+}
+
+// WithReplayDir serves requests from the recordings in dir instead of sending them to the API.
+func WithReplayDir(dir string) Option {
+	return func(c *ConnectorClient) {
+		c.replayDir = dir
+	}
+}
+
+// recorder names recordings after the operation and a hash of the request without its page sizes. Identical requests
+// are numbered in the order they are sent, so a replay returns the same sequence of responses as the recorded sync.
+type recorder struct {
+	dir    string
+	apiKey *apiKeySource
+
+	mu    sync.Mutex
+	seqs  map[string]int
+	dirOK bool
+	// cursors maps the cursors returned by the API to their alias in recordings.
+	cursors map[string]string
+}
+
+func newRecorder(dir string, apiKey *apiKeySource) *recorder {
+	return &recorder{
+		dir:     dir,
+		apiKey:  apiKey,
+		seqs:    make(map[string]int),
+		cursors: make(map[string]string),
+	}
+}
+
+// redact removes the API key from b.
+func (r *recorder) redact(b []byte) []byte {
+	return r.apiKey.redact(b)
+}
+
+// requestKey reads the body of req and returns it in its recorded form together with the operation name, whether
+// it is a mutation, and the file name prefix of its recordings. The body of req is restored so that it can still be
+// sent.
+func (r *recorder) requestKey(req *http.Request) (json.RawMessage, string, bool, string, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, "", false, "", err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	q := &Query{}
+	if err := json.Unmarshal(body, q); err != nil {
+		return nil, "", false, "", fmt.Errorf("twingate-client: recorded request is not a GraphQL query: %w", err)
+	}
+	operation := "anonymous"
+	mutation := false
+	if m := operationNameRe.FindStringSubmatch(q.Query); m != nil {
+		operation = m[2]
+		mutation = m[1] == "mutation"
+	}
+
+	normalized, err := normalizeJSON(r.redact(body), r.normalizeRequest)
+	if err != nil {
+		return nil, "", false, "", err
+	}
+	matched, err := normalizeJSON(normalized, withoutPageSizes)
+	if err != nil {
+		return nil, "", false, "", err
+	}
+	sum := sha256.Sum256(matched)
+	return normalized, operation, mutation, operation + "-" + hex.EncodeToString(sum[:6]), nil
+}
+
+// withoutPageSizes removes the page sizes from the variables of a request.
+func withoutPageSizes(v interface{}) interface{} {
+	body, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	variables, ok := body["variables"].(map[string]interface{})
+	if !ok {
+		return v
+	}
+	for name := range pageSizeVariables {
+		delete(variables, name)
+	}
+	return v
+}
+
+// normalizeRequest replaces the cursors sent in the variables of a request with their alias. Cursors the recorder
+// has not seen, such as the aliases sent during a replay, are kept as they are.
+func (r *recorder) normalizeRequest(v interface{}) interface{} {
+	body, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	variables, ok := body["variables"].(map[string]interface{})
+	if !ok {
+		return v
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, value := range variables {
+		if cursor, ok := value.(string); ok && cursorVariables[name] {
+			if alias, ok := r.cursors[cursor]; ok {
+				variables[name] = alias
+			}
+		}
+	}
+	return v
+}
+
+// normalizeResponse replaces the cursors of a response with their alias and, in responses to mutations, the
+// timestamps of the change with mutationTimestamp.
+func (r *recorder) normalizeResponse(mutation bool) func(v interface{}) interface{} {
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, field := range v {
+				s, isString := field.(string)
+				switch {
+				case isString && cursorFields[k]:
+					v[k] = r.cursorAlias(s)
+				case isString && mutation && mutationTimestampFields[k]:
+					v[k] = mutationTimestamp
+				default:
+					walk(field)
+				}
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	return func(v interface{}) interface{} {
+		walk(v)
+		return v
+	}
+}
+
+// cursorAlias returns the alias of cursor, assigning the next one if it was not seen before.
+func (r *recorder) cursorAlias(cursor string) string {
+	if cursor == "" {
+		return cursor
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	alias, ok := r.cursors[cursor]
+	if !ok {
+		alias = fmt.Sprintf("cursor-%d", len(r.cursors)+1)
+		r.cursors[cursor] = alias
+	}
+	return alias
+}
+
+// next returns the path of the next recording for key.
+func (r *recorder) next(key string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seq := r.seqs[key]
+	r.seqs[key] = seq + 1
+	return r.path(key, seq)
+}
+
+func (r *recorder) path(key string, seq int) string {
+	return filepath.Join(r.dir, fmt.Sprintf("%s-%06d.json", key, seq))
+}
+
+// normalizeJSON re-encodes b, rewritten by normalize, with sorted keys and indentation so that recordings are stable
+// and readable. Bodies that are not JSON are returned as a JSON string.
+func normalizeJSON(b []byte, normalize func(v interface{}) interface{}) (json.RawMessage, error) {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return json.Marshal(string(b))
+	}
+	return json.MarshalIndent(normalize(v), "", "  ")
+}
+
+type recordTransport struct {
+	next     http.RoundTripper
+	recorder *recorder
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, operation, mutation, key, err := t.recorder.requestKey(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	rec := &Recording{Operation: operation, Request: reqBody}
+	rec.Response.StatusCode = resp.StatusCode
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			if rec.Response.Headers == nil {
+				rec.Response.Headers = make(map[string]string)
+			}
+			rec.Response.Headers[h] = v
+		}
+	}
+	rec.Response.Body, err = normalizeJSON(t.recorder.redact(respBody), t.recorder.normalizeResponse(mutation))
+	if err != nil {
+		return nil, err
+	}
+	if err := t.recorder.write(key, rec); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *recorder) write(key string, rec *Recording) error {
+	b, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	if !r.dirOK {
+		if err := os.MkdirAll(r.dir, 0o700); err != nil {
+			r.mu.Unlock()
+			return fmt.Errorf("twingate-client: error creating record dir: %w", err)
+		}
+		r.dirOK = true
+	}
+	r.mu.Unlock()
+	return os.WriteFile(r.next(key), append(b, '\n'), 0o600)
+}
+
+type replayTransport struct {
+	recorder *recorder
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	_, operation, _, key, err := t.recorder.requestKey(req)
+	if err != nil {
+		return nil, err
+	}
+	path := t.recorder.next(key)
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// A replay must send exactly the requests of the recorded sync. Serving another recording instead would
+		// hide that the replayed sync differs from the recorded one.
+		if _, statErr := os.Stat(t.recorder.path(key, 0)); statErr == nil {
+			return nil, fmt.Errorf("twingate-client: %s request was sent more times than recorded in %s", operation, t.recorder.dir)
+		}
+		return nil, fmt.Errorf("twingate-client: no recording of %s request in %s matches it", operation, t.recorder.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("twingate-client: error reading recording of %s request: %w", operation, err)
+	}
+	rec := &Recording{}
+	if err := json.Unmarshal(b, rec); err != nil {
+		return nil, fmt.Errorf("twingate-client: invalid recording %s: %w", path, err)
+	}
+
+	body := []byte(rec.Response.Body)
+	var s string
+	if err := json.Unmarshal(body, &s); err == nil {
+		body = []byte(s)
+	}
+	header := make(http.Header)
+	for k, v := range rec.Response.Headers {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Response.StatusCode, http.StatusText(rec.Response.StatusCode)),
+		StatusCode:    rec.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
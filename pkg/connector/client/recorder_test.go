@@ -0,0 +1,132 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listAllUsers returns the IDs of every user, listed one page of pageSize at a time.
+func listAllUsers(t *testing.T, c *ConnectorClient, pageSize uint32) []string {
+	t.Helper()
+	var ids []string
+	cursor := ""
+	for {
+		resp, err := c.ListUsers(context.Background(), cursor, pageSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, u := range resp.Nodes {
+			ids = append(ids, u.ID)
+		}
+		if cursor = resp.Pagination; cursor == "" {
+			return ids
+		}
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	c, _ := newTestClient(t, testDataset(3), WithRecordDir(dir))
+	recorded := listAllUsers(t, c, 1)
+	if _, err := c.RenameGroup(ctx, "R3JvdXA6MQ==", "renamed"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all []byte
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, b...)
+	}
+	if bytes.Contains(all, []byte(testAPIKey)) {
+		t.Error("recordings contain the api key")
+	}
+	for _, want := range []string{`"endCursor": "cursor-1"`, `"after": "cursor-1"`, `"updatedAt": "` + mutationTimestamp + `"`} {
+		if !bytes.Contains(all, []byte(want)) {
+			t.Errorf("recordings do not contain %s", want)
+		}
+	}
+
+	replay, err := New(ctx, testAPIKey, "example", WithReplayDir(dir), WithRetryPolicy(RetryPolicy{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed := listAllUsers(t, replay, 1); !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("replay listed users %v, recording listed %v", replayed, recorded)
+	}
+	if _, err := replay.RenameGroup(ctx, "R3JvdXA6MQ==", "renamed"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = replay.ListUsers(ctx, "", 1)
+	if err == nil || !strings.Contains(err.Error(), "more times than recorded") {
+		t.Errorf("replaying a request more times than recorded returned %v", err)
+	}
+	_, err = replay.ListGroups(ctx, "", 1)
+	if err == nil || !strings.Contains(err.Error(), "no recording") {
+		t.Errorf("replaying a request that was not recorded returned %v", err)
+	}
+}
+
+// listUsersWithPageSizes returns the IDs of every user and the page sizes they were listed with, taking the size of
+// each page from PageSize as a sync does.
+func listUsersWithPageSizes(t *testing.T, c *ConnectorClient) ([]string, []uint32) {
+	t.Helper()
+	var ids []string
+	var sizes []uint32
+	cursor := ""
+	for {
+		size := c.PageSize(ConnectionUsers, 0)
+		sizes = append(sizes, size)
+		resp, err := c.ListUsers(context.Background(), cursor, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, u := range resp.Nodes {
+			ids = append(ids, u.ID)
+		}
+		if cursor = resp.Pagination; cursor == "" {
+			return ids, sizes
+		}
+	}
+}
+
+func TestReplayWithAdaptedPageSizes(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	// Every fast page grows the next one.
+	growing := PageSizePolicy{Initial: 2, Min: 1, Max: 100, FastLatency: time.Minute, GrowAfter: 1}
+	c, _ := newTestClient(t, testDataset(20), WithRecordDir(dir), WithPageSizePolicy(growing))
+	recorded, recordedSizes := listUsersWithPageSizes(t, c)
+	if len(recordedSizes) < 3 || recordedSizes[0] == recordedSizes[len(recordedSizes)-1] {
+		t.Fatalf("the recorded sync did not adapt its page sizes: %v", recordedSizes)
+	}
+
+	// The replay starts from other sizes and adapts them to its own latency, and still gets the recorded pages.
+	other := growing
+	other.Initial = 5
+	replay, err := New(ctx, testAPIKey, "example", WithReplayDir(dir), WithRetryPolicy(RetryPolicy{}), WithPageSizePolicy(other))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, replayedSizes := listUsersWithPageSizes(t, replay)
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Errorf("replay listed users %v, recording listed %v", replayed, recorded)
+	}
+	if reflect.DeepEqual(replayedSizes, recordedSizes) {
+		t.Errorf("replay asked for the recorded page sizes %v, want other sizes", recordedSizes)
+	}
+}
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// RetryPolicy controls how requests that fail for a transient reason are retried. Waits between attempts grow by
+// Multiplier from InitialInterval up to MaxInterval, each shortened by a random jitter of up to half, and no attempt
+// is started once MaxElapsedTime has passed since the first one. A request rejected because of the rate limit waits
+// at least as long as its Retry-After header asks, even beyond MaxInterval. A zero RetryPolicy never retries.
+type RetryPolicy struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	MaxElapsedTime  time.Duration
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy is given.
+var DefaultRetryPolicy = RetryPolicy{
+	InitialInterval: 500 * time.Millisecond,
+	MaxInterval:     10 * time.Second,
+	Multiplier:      2,
+	MaxElapsedTime:  time.Minute,
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *ConnectorClient) {
+		c.retryPolicy = policy
+	}
+}
+
+// failureClass says whether a failed request may be retried.
+type failureClass int
+
+const (
+	// failurePermanent is not retried.
+	failurePermanent failureClass = iota
+	// failureNotSent means the request was never run, for example because the connection was refused or Twingate
+	// rejected it because of the rate limit. Retrying is safe for every operation.
+	failureNotSent
+	// failureTransient means the request may have reached Twingate, for example a reset connection or a 503.
+	// Only queries are retried, since a mutation may already have been applied.
+	failureTransient
+)
+
+// retryableError marks an error with its failure class.
+type retryableError struct {
+	class failureClass
+	// timeout is set when the request timed out, which a smaller page may avoid.
+	timeout bool
+	// retryAfter is the wait asked for by the Retry-After header of the response, if any.
+	retryAfter time.Duration
+	err        error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+var mutationRe = regexp.MustCompile(`^\s*mutation\b`)
+
+// classifyTransportError returns the failure class of an error returned by the HTTP client.
+func classifyTransportError(err error) failureClass {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) || errors.Is(err, syscall.ECONNREFUSED) {
+		return failureNotSent
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return failureNotSent
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return failureTransient
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return failureTransient
+	}
+	return failurePermanent
+}
+
+// isTimeout reports whether err is a timeout of the request, as opposed to the context deadline of the caller.
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// classifyStatusCode returns the failure class of an HTTP status code other than 200.
+func classifyStatusCode(statusCode int) failureClass {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		// Twingate rejects rate limited requests before running them.
+		return failureNotSent
+	case 500, 502, 503, 504:
+		return failureTransient
+	default:
+		return failurePermanent
+	}
+}
+
+// parseRetryAfter returns the wait asked for by a Retry-After header, given in seconds or as an HTTP date, or zero
+// when there is none.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// retry calls attempt until it succeeds, fails with an error that may not be retried for this operation, or the
+// policy gives up. The error of the last attempt is returned.
+func (c *ConnectorClient) retry(ctx context.Context, rawQuery string, attempt func() error) error {
+	policy := c.retryPolicy
+	mutation := mutationRe.MatchString(rawQuery)
+	start := time.Now()
+	interval := policy.InitialInterval
+	for n := 1; ; n++ {
+		err := attempt()
+		if err == nil {
+			return nil
+		}
+		var re *retryableError
+		if !errors.As(err, &re) || re.class == failurePermanent || (mutation && re.class != failureNotSent) {
+			return err
+		}
+		if re.timeout && ctx.Value(shrinkOnTimeoutKey{}) != nil {
+			// fetchPage fetches the page again with a smaller size instead.
+			return err
+		}
+		if policy.MaxElapsedTime <= 0 || ctx.Err() != nil {
+			return err
+		}
+
+		wait := interval
+		if wait > policy.MaxInterval && policy.MaxInterval > 0 {
+			wait = policy.MaxInterval
+		}
+		if wait > 0 {
+			wait -= time.Duration(rand.Int63n(int64(wait)/2 + 1)) //nolint:gosec // jitter does not need a secure source
+		}
+		if re.retryAfter > wait {
+			wait = re.retryAfter
+		}
+		if time.Since(start)+wait > policy.MaxElapsedTime {
+			return err
+		}
+		ctxzap.Extract(ctx).Debug("twingate-client: retrying request",
+			zap.Int("attempt", n),
+			zap.Duration("wait", wait),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		interval = time.Duration(float64(interval) * policy.Multiplier)
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+)
+
+// fastRetries retries quickly, so that tests do not wait for the backoff.
+var fastRetries = RetryPolicy{
+	InitialInterval: time.Millisecond,
+	MaxInterval:     5 * time.Millisecond,
+	Multiplier:      2,
+	MaxElapsedTime:  10 * time.Second,
+}
+
+func TestRetryTransientFaults(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(3), WithRetryPolicy(fastRetries))
+	fake.InjectFault(twingatefake.Fault{Operation: "getUsers", StatusCode: http.StatusBadGateway, Message: "bad gateway", Count: 2})
+	fake.InjectFault(twingatefake.Fault{Operation: "getGroups", StatusCode: http.StatusServiceUnavailable, Message: "service unavailable", Count: 1})
+	fake.InjectFault(twingatefake.Fault{Operation: "getGroupMembers", Drop: true, Count: 1})
+
+	users, err := c.ListUsers(ctx, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users.Nodes) != 3 {
+		t.Errorf("got %d users, want 3", len(users.Nodes))
+	}
+	if _, err := c.ListGroups(ctx, "", 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListGroupGrants(ctx, testGroupID, "", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	for op, want := range map[string]int{"getUsers": 3, "getGroups": 2, "getGroupMembers": 2} {
+		if calls := fake.Calls(op); calls != want {
+			t.Errorf("sent %s %d times, want %d", op, calls, want)
+		}
+	}
+}
+
+func TestRetryRateLimited(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(1), WithRetryPolicy(fastRetries))
+	fake.InjectFault(twingatefake.Fault{Operation: "getUsers", StatusCode: http.StatusTooManyRequests, Message: "rate limited", Count: 2})
+
+	users, err := c.ListUsers(ctx, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users.Nodes) != 1 {
+		t.Errorf("got %d users, want 1", len(users.Nodes))
+	}
+	if users.RateLimitDescription.GetStatus() != v2.RateLimitDescription_STATUS_OK {
+		t.Errorf("rate limit description is %v, want OK", users.RateLimitDescription)
+	}
+	if calls := fake.Calls("getUsers"); calls != 3 {
+		t.Errorf("sent getUsers %d times, want 3", calls)
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(1), WithRetryPolicy(fastRetries))
+	fake.InjectFault(twingatefake.Fault{Operation: "getUsers", StatusCode: http.StatusTooManyRequests, Message: "rate limited", RetryAfter: time.Second, Count: 1})
+
+	start := time.Now()
+	if _, err := c.ListUsers(ctx, "", 10); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, before the Retry-After of 1s", elapsed)
+	}
+}
+
+func TestRateLimitedOnceRetriesAreUsedUp(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(1), WithRetryPolicy(DefaultRetryPolicy))
+	// Twingate asks for a longer wait than the policy retries for, so the client gives up at once.
+	fake.InjectFault(twingatefake.Fault{Operation: "getUsers", StatusCode: http.StatusTooManyRequests, Message: "rate limited", RetryAfter: 2 * time.Minute})
+
+	start := time.Now()
+	_, err := c.ListUsers(ctx, "", 10)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("got error %v, want ErrRateLimited", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("gave up after %s", elapsed)
+	}
+	if calls := fake.Calls("getUsers"); calls != 1 {
+		t.Errorf("sent getUsers %d times, want 1", calls)
+	}
+
+	rl := RateLimitDescription(err)
+	if rl.GetStatus() != v2.RateLimitDescription_STATUS_OVERLIMIT {
+		t.Fatalf("rate limit description is %v, want OVERLIMIT", rl)
+	}
+	if resetAt := rl.GetResetAt().AsTime(); resetAt.Before(start.Add(time.Minute)) {
+		t.Errorf("rate limit resets at %s, before the Retry-After of 2m", resetAt)
+	}
+}
+
+func TestRetryMutations(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(2), WithRetryPolicy(fastRetries))
+
+	// A rate limited mutation was not run, so it is sent again.
+	fake.InjectFault(twingatefake.Fault{Operation: "updateGroupMembers", StatusCode: http.StatusTooManyRequests, Message: "rate limited", Count: 1})
+	if _, err := c.updateGroupMembers(ctx, testGroupID, []string{"User:0"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if calls := fake.Calls("updateGroupMembers"); calls != 2 {
+		t.Errorf("sent the rate limited mutation %d times, want 2", calls)
+	}
+
+	// A mutation that failed with a 502 may have been applied, so it is not.
+	fake.InjectFault(twingatefake.Fault{Operation: "updateGroupMembers", StatusCode: http.StatusBadGateway, Message: "bad gateway", Count: 1})
+	if _, err := c.updateGroupMembers(ctx, testGroupID, []string{"User:1"}, nil); err == nil {
+		t.Fatal("a mutation that failed with a 502 succeeded")
+	}
+	if calls := fake.Calls("updateGroupMembers"); calls != 3 {
+		t.Errorf("sent the failed mutation %d times, want 1", calls-2)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"":                              0,
+		"30":                            30 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Thu, 01 Jan 1970 00:00:00 GMT": 0,
+	} {
+		if got := parseRetryAfter(value); got != want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", value, got, want)
+		}
+	}
+	at := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(at); got < 59*time.Minute || got > time.Hour {
+		t.Errorf("parseRetryAfter(%q) = %s, want about 1h", at, got)
+	}
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/conductorone/baton-sdk/pkg/sdk"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// TransportConfig configures how requests reach the Twingate API, for networks where egress goes through a proxy
+// that inspects TLS. Empty fields keep the defaults: the proxy from the HTTPS_PROXY environment variable and the
+// system certificate pool.
+type TransportConfig struct {
+	// ProxyURL is the http, https or socks5 URL of the proxy requests are sent through.
+	ProxyURL string
+	// ProxyUsername and ProxyPassword authenticate with the proxy, taking precedence over credentials in ProxyURL.
+	ProxyUsername string
+	ProxyPassword string
+	// CAFile is a PEM bundle of certificates trusted in addition to the system pool, such as the certificate of a
+	// proxy that inspects TLS.
+	CAFile string
+	// ClientCertFile and ClientKeyFile are a PEM certificate and key presented to servers and proxies that ask for
+	// one.
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+// WithTransport sends requests as configured by config.
+func WithTransport(config TransportConfig) Option {
+	return func(c *ConnectorClient) {
+		c.transport = config
+	}
+}
+
+// Validate returns an error when the proxy URL is invalid or a certificate file cannot be loaded.
+func (t TransportConfig) Validate() error {
+	if _, err := t.proxyURL(); err != nil {
+		return err
+	}
+	_, err := t.tlsConfig()
+	return err
+}
+
+// proxyURL returns the proxy URL with the configured credentials, or nil when no proxy is configured.
+func (t TransportConfig) proxyURL() (*url.URL, error) {
+	if t.ProxyURL == "" {
+		if t.ProxyUsername != "" || t.ProxyPassword != "" {
+			return nil, fmt.Errorf("twingate-client: proxy credentials are set without a proxy url")
+		}
+		return nil, nil
+	}
+	u, err := url.Parse(t.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("twingate-client: invalid proxy url: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("twingate-client: invalid proxy url %q: expected an http, https or socks5 URL", u.Redacted())
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("twingate-client: invalid proxy url %q: the host is missing", u.Redacted())
+	}
+	if t.ProxyUsername != "" || t.ProxyPassword != "" {
+		u.User = url.UserPassword(t.ProxyUsername, t.ProxyPassword)
+	}
+	return u, nil
+}
+
+// tlsConfig returns the TLS configuration with the extra CA certificates and the client certificate.
+func (t TransportConfig) tlsConfig() (*tls.Config, error) {
+	rv := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("twingate-client: error reading ca file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("twingate-client: ca file %s holds no PEM certificates", t.CAFile)
+		}
+		rv.RootCAs = pool
+	}
+	if (t.ClientCertFile == "") != (t.ClientKeyFile == "") {
+		return nil, fmt.Errorf("twingate-client: the client certificate and key must be set together")
+	}
+	if t.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.ClientCertFile, t.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("twingate-client: error loading client certificate: %w", err)
+		}
+		rv.Certificates = []tls.Certificate{cert}
+	}
+	return rv, nil
+}
+
+// userAgent is sent with every request, like the user agent uhttp sends for the SDK.
+var userAgent = "baton-twingate baton-sdk/" + sdk.Version
+
+// newClient returns the HTTP client for the API. uhttp, the HTTP client of the SDK, always takes the proxy from the
+// environment, so the transport is built here, on the settings of http.DefaultTransport that uhttp is based on, and
+// wrapped in a loggingTransport that does what the uhttp wrapper does. With or without a configured proxy requests
+// go through the same transport, logging and user agent.
+func newClient(_ context.Context, config TransportConfig) (*http.Client, error) {
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	proxyURL, err := config.proxyURL()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if proxyURL != nil {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: &loggingTransport{next: transport, userAgent: userAgent}}, nil
+}
+
+// loggingTransport sets the user agent of requests and logs them at debug level with the logger of their context,
+// with the messages and fields of uhttp.
+type loggingTransport struct {
+	next      http.RoundTripper
+	userAgent string
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l := ctxzap.Extract(req.Context())
+	fields := []zap.Field{
+		zap.String("http.method", req.Method),
+		zap.String("http.url_details.host", req.URL.Host),
+		zap.String("http.url_details.path", req.URL.Path),
+	}
+	l.Debug("Request started", fields...)
+
+	if req.Header.Get("User-Agent") == "" {
+		// RoundTrippers must not modify the request they are given.
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	if resp != nil {
+		fields = append(fields, zap.Int("http.status_code", resp.StatusCode))
+	}
+	l.Debug("Request complete", fields...)
+	return resp, err
+}
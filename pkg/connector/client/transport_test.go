@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// userAgents records the user agent of the requests it serves.
+type userAgents struct {
+	next http.Handler
+	mu   sync.Mutex
+	seen []string
+}
+
+func (u *userAgents) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.mu.Lock()
+	u.seen = append(u.seen, r.UserAgent())
+	u.mu.Unlock()
+	u.next.ServeHTTP(w, r)
+}
+
+// requestLogs lists one page of users with a logger in the context, and returns the messages logged for the requests.
+func requestLogs(t *testing.T, c *ConnectorClient) []string {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	ctx := ctxzap.ToContext(context.Background(), zap.New(core))
+	if _, err := c.ListUsers(ctx, "", 10); err != nil {
+		t.Fatal(err)
+	}
+	var rv []string
+	for _, entry := range logs.FilterField(zap.String("http.method", http.MethodPost)).All() {
+		rv = append(rv, entry.Message)
+	}
+	return rv
+}
+
+func TestTransportIsTheSameWithAProxy(t *testing.T) {
+	ctx := context.Background()
+	agents := &userAgents{next: twingatefake.New(testDataset(1), twingatefake.WithAPIKey(testAPIKey))}
+	server := httptest.NewServer(agents)
+	t.Cleanup(server.Close)
+	proxy := twingatefake.NewProxy("baton", "proxy-password")
+	proxyServer := httptest.NewServer(proxy)
+	t.Cleanup(proxyServer.Close)
+
+	var logs [][]string
+	for _, transport := range []TransportConfig{
+		{},
+		{ProxyURL: proxyServer.URL, ProxyUsername: "baton", ProxyPassword: "proxy-password"},
+	} {
+		c, err := New(ctx, testAPIKey, "example", WithAPIURL(server.URL+"/api/graphql/"), WithTransport(transport), WithRetryPolicy(RetryPolicy{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, requestLogs(t, c))
+	}
+
+	if proxy.Requests() != 1 {
+		t.Errorf("%d requests went through the proxy, want 1", proxy.Requests())
+	}
+	if len(agents.seen) != 2 || agents.seen[0] != userAgent || agents.seen[1] != userAgent {
+		t.Errorf("got user agents %q, want %q for both requests", agents.seen, userAgent)
+	}
+	want := []string{"Request started", "Request complete"}
+	for i, name := range []string{"without a proxy", "through the proxy"} {
+		if len(logs[i]) != len(want) || logs[i][0] != want[0] || logs[i][1] != want[1] {
+			t.Errorf("request %s logged %q, want %q", name, logs[i], want)
+		}
+	}
+}
+
+func TestTransportThroughProxyWithClientCertificate(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	clientCert, err := newClientCertificate(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(twingatefake.New(testDataset(1), twingatefake.WithAPIKey(testAPIKey)))
+	clientCert.startTLS(server)
+	t.Cleanup(server.Close)
+	proxy := twingatefake.NewProxy("baton", "proxy-password")
+	proxyServer := httptest.NewServer(proxy)
+	t.Cleanup(proxyServer.Close)
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := writeCAFile(caFile, []*httptest.Server{server}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New(ctx, testAPIKey, "example", WithAPIURL(server.URL+"/api/graphql/"), WithRetryPolicy(RetryPolicy{}), WithTransport(TransportConfig{
+		ProxyURL:       proxyServer.URL,
+		ProxyUsername:  "baton",
+		ProxyPassword:  "proxy-password",
+		CAFile:         caFile,
+		ClientCertFile: clientCert.certFile,
+		ClientKeyFile:  clientCert.keyFile,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListUsers(ctx, "", 10); err != nil {
+		t.Fatal(err)
+	}
+	if proxy.Requests() == 0 {
+		t.Error("no request went through the proxy")
+	}
+
+	// Without the client certificate the server refuses the connection.
+	c, err = New(ctx, testAPIKey, "example", WithAPIURL(server.URL+"/api/graphql/"), WithRetryPolicy(RetryPolicy{}), WithTransport(TransportConfig{
+		ProxyURL:      proxyServer.URL,
+		ProxyUsername: "baton",
+		ProxyPassword: "proxy-password",
+		CAFile:        caFile,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListUsers(ctx, "", 10); err == nil {
+		t.Error("a request without the client certificate succeeded")
+	}
+}
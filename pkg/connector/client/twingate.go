@@ -1,18 +1,22 @@
 package client
 
+//go:generate go run -C ../../../tools github.com/Khan/genqlient ../pkg/connector/client/genqlient.yaml
+
 import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Khan/genqlient/graphql"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
-	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -23,78 +27,31 @@ const (
 	rateLimit = 20 // TODO(mstanbCO) Change this back to 60
 )
 
+// ErrNotFound is returned when Twingate reports that the requested object does not exist.
+var ErrNotFound = errors.New("twingate-client: not found")
+
+// ErrPermissionDenied is returned when the API key is not allowed to run a query or mutation.
+var ErrPermissionDenied = errors.New("twingate-client: permission denied")
+
+// ErrUnauthorized is returned when Twingate rejects the API key.
+var ErrUnauthorized = errors.New("twingate-client: invalid api key")
+
 type Role struct {
 	Name string
 	Id   string
 }
 
 type Query struct {
-	Query     string            `json:"query"`
-	Variables map[string]string `json:"variables,omitempty"`
-}
-
-type UsersQueryResponse struct {
-	Data struct {
-		Users struct {
-			Edges []struct {
-				User *User `json:"node"`
-			} `json:"edges"`
-			Pagination PageInfo `json:"pageInfo"`
-		} `json:"users"`
-	} `json:"data"`
-}
-
-type GrantAndRevokeGroupResponse struct {
-	Data struct {
-		GroupUpdate struct {
-			Ok    bool    `json:"ok"`
-			Error *string `json:"error"`
-		} `json:"groupUpdate"`
-	} `json:"data"`
-}
-
-type GroupsQueryResponse struct {
-	Data struct {
-		Groups struct {
-			Edges []struct {
-				Group *Group `json:"node"`
-			} `json:"edges"`
-			Pagination PageInfo `json:"pageInfo"`
-		} `json:"groups"`
-	} `json:"data"`
-}
-
-type RolesQueryResponse struct {
-	Data struct {
-		Roles []*Role `json:"roles"`
-	} `json:"data"`
-}
-
-type GroupMembersQueryResponse struct {
-	Data struct {
-		Group struct {
-			Id    string `json:"id"`
-			Name  string `json:"name"`
-			Users struct {
-				Edges []struct {
-					User *User `json:"node"`
-				} `json:"edges"`
-			} `json:"users"`
-		} `json:"group"`
-	} `json:"data"`
-}
-
-type RoleGrantsQueryResponse struct {
-	Data struct {
-		Users []struct {
-			Id       string `json:"id"`
-			Email    string `json:"email"`
-			Fullname string `json:"fullname"`
-			Roles    []struct {
-				Id int `json:"id"`
-			} `json:"roles"`
-		} `json:"users"`
-	} `json:"data"`
+	Query     string      `json:"query"`
+	Variables interface{} `json:"variables,omitempty"`
+}
+
+type GraphQLError struct {
+	Message string `json:"message"`
+}
+
+type ErrorsResponse struct {
+	Errors []GraphQLError `json:"errors"`
 }
 
 type User struct {
@@ -103,6 +60,12 @@ type User struct {
 	FirstName string `json:"firstName"`
 	LastName  string `json:"lastName"`
 	IsAdmin   bool   `json:"isAdmin"`
+	Role      string `json:"role"`
+	State     string `json:"state"`
+	// Type is SYNCED for users provisioned from an identity provider and MANUAL for the others.
+	Type      string `json:"type"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
 }
 
 type PageInfo struct {
@@ -111,9 +74,15 @@ type PageInfo struct {
 }
 
 type Group struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	IsActive bool   `json:"isActive,omitempty"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	IsActive  bool   `json:"isActive,omitempty"`
+	Type      string `json:"type,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+	// MemberCount is the number of members of the group, or nil when the query that returned the group did not count
+	// them.
+	MemberCount *int `json:"memberCount,omitempty"`
 }
 
 var defaultRoles = []*Role{{Name: "Admin", Id: "admin"}, {Name: "Member", Id: "member"}}
@@ -123,86 +92,227 @@ type GroupGrant struct {
 	PrincipalID string
 }
 
-type RoleGrant struct {
-	RoleID      string
-	PrincipalID string
+type InfoResponse struct {
+	User                 *User
+	RateLimitDescription *v2.RateLimitDescription
 }
 
-type InfoResponse struct {
+type UsersResponse = Connection[*User]
+
+type GroupGrantsResponse = Connection[GroupGrant]
+
+type UserResponse struct {
 	User                 *User
 	RateLimitDescription *v2.RateLimitDescription
 }
 
-type UsersResponse struct {
-	Users                []*User
+type CreateUserResponse struct {
+	User                 *User
+	RateLimitDescription *v2.RateLimitDescription
+}
+
+type UpdateUserStateResponse struct {
+	User                 *User
 	RateLimitDescription *v2.RateLimitDescription
-	Pagination           string
 }
 
-type RoleGrantsResponse struct {
-	Grants               []RoleGrant
+type DeleteUserResponse struct {
 	RateLimitDescription *v2.RateLimitDescription
-	Pagination           string
 }
 
-type GroupGrantsResponse struct {
-	Grants               []GroupGrant
+type GroupResponse struct {
+	Group                *Group
+	RateLimitDescription *v2.RateLimitDescription
+}
+
+type GroupMutationResponse struct {
 	RateLimitDescription *v2.RateLimitDescription
-	Pagination           string
 }
 
 type GrantEntitlementResponse struct {
+	// AlreadyExists is set when the user was already a member of the group.
+	AlreadyExists        bool
 	RateLimitDescription *v2.RateLimitDescription
 }
 
 type RevokeEntitlementResponse struct {
+	// AlreadyRevoked is set when the user was not a member of the group or no longer exists.
+	AlreadyRevoked       bool
 	RateLimitDescription *v2.RateLimitDescription
 }
 
-type GroupResourcesResponse struct {
-	Groups               []Group
-	RateLimitDescription *v2.RateLimitDescription
-	Pagination           string
+type GroupResourcesResponse = Connection[Group]
+
+// GroupWithMembers is a group together with the first page of its members.
+type GroupWithMembers struct {
+	Group
+	MemberIDs []string
+	// MembersPagination is the cursor for the remaining members, or empty when MemberIDs holds all of them.
+	MembersPagination string
 }
 
+type GroupsWithMembersResponse = Connection[GroupWithMembers]
+
 type Client interface {
-	ListUsers(ctx context.Context, pagination string) (*UsersResponse, error)
-	ListRoles(ctx context.Context, pagination string) ([]*Role, error)
-	ListGroups(ctx context.Context, pagination string) (*GroupResourcesResponse, error)
-	ListRoleGrants(ctx context.Context, roleID string, pagination string) (*RoleGrantsResponse, error)
-	ListGroupGrants(ctx context.Context, groupID string, pagination string) (*GroupGrantsResponse, error)
+	ListUsers(ctx context.Context, pagination string, pageSize uint32) (*UsersResponse, error)
+	ListRoles(ctx context.Context) ([]*Role, error)
+	ListGroups(ctx context.Context, pagination string, pageSize uint32) (*GroupResourcesResponse, error)
+	ListGroupGrants(ctx context.Context, groupID string, pagination string, pageSize uint32) (*GroupGrantsResponse, error)
 }
 
 type ConnectorClient struct {
-	Domain                string
+	// Domain is the hostname of the Twingate network, as returned by NormalizeDomain.
+	Domain string
+	// APIURL overrides the GraphQL endpoint derived from Domain when set.
+	APIURL                string
 	Client                *http.Client
-	ApiKey                string
+	apiKey                *apiKeySource
+	rateLimitMu           sync.Mutex
 	rateLimitBucket       int64
 	rateLimitRequestCount int64
+	retryPolicy           RetryPolicy
+	pageSizes             *pageSizer
+	memberships           *membershipQueue
+	transport             TransportConfig
+	// recordDir and replayDir wrap the transport of Client once it is built.
+	recordDir string
+	replayDir string
 }
 
-func New(ctx context.Context, apiKey string, domain string) (*ConnectorClient, error) {
-	client, err := newClient(ctx)
+// Option configures optional behaviour of a ConnectorClient.
+type Option func(c *ConnectorClient)
+
+// WithAPIURL sends requests to apiURL instead of the GraphQL endpoint of the Twingate domain.
+func WithAPIURL(apiURL string) Option {
+	return func(c *ConnectorClient) {
+		c.APIURL = apiURL
+	}
+}
+
+func New(ctx context.Context, apiKey string, domain string, opts ...Option) (*ConnectorClient, error) {
+	if domain != "" {
+		var err error
+		domain, err = NormalizeDomain(domain)
+		if err != nil {
+			return nil, err
+		}
+	}
+	key, err := newAPIKeySource(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	rv := &ConnectorClient{
+		Domain:      domain,
+		apiKey:      key,
+		retryPolicy: DefaultRetryPolicy,
+		pageSizes:   newPageSizer(),
+	}
+	rv.memberships = newMembershipQueue(rv)
+	for _, opt := range opts {
+		opt(rv)
+	}
+	client, err := newClient(ctx, rv.transport)
 	if err != nil {
 		return nil, err
 	}
-	return &ConnectorClient{
-		Domain: domain,
-		Client: client,
-		ApiKey: apiKey,
-	}, nil
+	rv.Client = client
+	if rv.recordDir != "" {
+		rv.Client.Transport = &recordTransport{
+			next:     rv.Client.Transport,
+			recorder: newRecorder(rv.recordDir, rv.apiKey),
+		}
+	}
+	if rv.replayDir != "" {
+		rv.Client.Transport = &replayTransport{
+			recorder: newRecorder(rv.replayDir, rv.apiKey),
+		}
+	}
+	if rv.APIURL != "" {
+		if err := validateAPIURL(rv.APIURL); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := rv.apiKey.get(); err != nil {
+		return nil, err
+	}
+	rv.apiKey.watch(ctx)
+	return rv, nil
 }
 
-func newClient(ctx context.Context) (*http.Client, error) {
-	httpClient, err := uhttp.NewClient(ctx, uhttp.WithLogger(true, nil))
+// Close releases the resources of the client, such as the watch of the api key file. The watch also ends when the
+// context passed to New is done.
+func (c *ConnectorClient) Close() error {
+	return c.apiKey.Close()
+}
+
+// NormalizeDomain returns the hostname of a Twingate network given as a network name such as "acme", a hostname
+// such as "acme.twingate.com", or an https URL such as "https://acme.twingate.com/". Hostnames outside of
+// twingate.com, such as staging networks, are kept as they are.
+func NormalizeDomain(domain string) (string, error) {
+	host := strings.TrimSpace(domain)
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return "", fmt.Errorf("twingate-client: invalid domain %q: %w", domain, err)
+		}
+		if u.Scheme != "https" {
+			return "", fmt.Errorf("twingate-client: invalid domain %q: only https is supported, use the api url for other endpoints", domain)
+		}
+		if strings.Trim(u.Path, "/") != "" || u.RawQuery != "" || u.User != nil {
+			return "", fmt.Errorf("twingate-client: invalid domain %q: expected a hostname, use the api url to set a full endpoint", domain)
+		}
+		host = u.Host
+	}
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(host, "/"), "."))
+	if host == "" || strings.ContainsAny(host, " /?#@") {
+		return "", fmt.Errorf("twingate-client: invalid domain %q", domain)
+	}
+	if !strings.Contains(host, ".") {
+		host = fmt.Sprintf(APIDomain, host)
+	}
+	return host, nil
+}
+
+// validateAPIURL checks that an api url override is an absolute http or https URL.
+func validateAPIURL(apiURL string) error {
+	u, err := url.Parse(apiURL)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("twingate-client: invalid api url %q: %w", apiURL, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("twingate-client: invalid api url %q: expected an absolute http or https URL", apiURL)
+	}
+	return nil
+}
+
+// endpoint returns the URL of the GraphQL API.
+func (c *ConnectorClient) endpoint() string {
+	if c.APIURL != "" {
+		return c.APIURL
 	}
-	return httpClient, nil
+	reqUrl := url.URL{Scheme: "https", Host: c.Domain, Path: strings.Join([]string{APIPath, Path, ""}, "/")}
+	return reqUrl.String()
+}
+
+// graphqlClient sends the operations generated from operations.graphql through query. graphql.Client has no way to
+// return the rate limit description of a response, so it is kept on the graphqlClient, which is used for a single
+// request.
+type graphqlClient struct {
+	client               *ConnectorClient
+	rateLimitDescription *v2.RateLimitDescription
+}
+
+func (c *ConnectorClient) graphql() *graphqlClient {
+	return &graphqlClient{client: c}
+}
+
+func (g *graphqlClient) MakeRequest(ctx context.Context, req *graphql.Request, resp *graphql.Response) error {
+	rateLimitDescription, err := g.client.query(ctx, req.Query, resp, req.Variables)
+	g.rateLimitDescription = rateLimitDescription
+	return err
 }
 
-func (c *ConnectorClient) query(ctx context.Context, rawQuery string, res interface{}, variables map[string]string) (*v2.RateLimitDescription, error) {
-	reqUrl := url.URL{Scheme: "https", Host: fmt.Sprintf(APIDomain, c.Domain), Path: strings.Join([]string{APIPath, Path, ""}, "/")}
+func (c *ConnectorClient) query(ctx context.Context, rawQuery string, res interface{}, variables interface{}) (*v2.RateLimitDescription, error) {
 	q := &Query{
 		Query:     rawQuery,
 		Variables: variables,
@@ -211,26 +321,28 @@ func (c *ConnectorClient) query(ctx context.Context, rawQuery string, res interf
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqUrl.String(), bytes.NewReader(b))
+	var rawResp []byte
+	err = c.retry(ctx, rawQuery, func() error {
+		_, rawResp, err = c.send(ctx, b)
+		return err
+	})
 	if err != nil {
+		var re *retryableError
+		if errors.Is(err, ErrRateLimited) && errors.As(err, &re) {
+			rateLimitDescription := c.getRateLimitDescription(ctx, true)
+			if re.retryAfter > 0 {
+				rateLimitDescription.ResetAt = timestamppb.New(time.Now().Add(re.retryAfter))
+			}
+			return rateLimitDescription, &rateLimitError{rateLimitDescription: rateLimitDescription, err: err}
+		}
 		return nil, err
 	}
-	req.Header["X-API-KEY"] = []string{c.ApiKey}
-	req.Header["Content-Type"] = []string{"application/json"}
-	resp, err := c.Client.Do(req)
-	if err != nil {
+	errResp := &ErrorsResponse{}
+	if err := json.Unmarshal(rawResp, errResp); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	rawResp, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusTooManyRequests {
-		return nil, fmt.Errorf("twingate-client: GraphQL HTTP request failed %d %s", resp.StatusCode, string(rawResp))
-	}
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return c.getRateLimitDescription(ctx, true), nil
+	if len(errResp.Errors) > 0 {
+		return nil, graphQLError(errResp.Errors)
 	}
 	if err := json.Unmarshal(rawResp, res); err != nil {
 		return nil, err
@@ -239,57 +351,184 @@ func (c *ConnectorClient) query(ctx context.Context, rawQuery string, res interf
 	return c.getRateLimitDescription(ctx, false), nil
 }
 
-func (c *ConnectorClient) ListUsers(ctx context.Context, pagination string, pageSize uint32) (*UsersResponse, error) {
-	var pagePointer *string = nil
-	if pagination != "" {
-		pagePointer = &pagination
+// send posts a marshalled query and returns the status code and the body of the response, with the API key
+// redacted. Errors that may be retried are returned as a retryableError.
+func (c *ConnectorClient) send(ctx context.Context, body []byte) (int, []byte, error) {
+	apiKey, err := c.apiKey.get()
+	if err != nil {
+		return 0, nil, err
+	}
+	statusCode, rawResp, err := c.sendOnce(ctx, body, apiKey)
+	if !errors.Is(err, ErrUnauthorized) {
+		return statusCode, rawResp, err
 	}
-	resp := &UsersQueryResponse{}
-	rateLimitDescription, err := c.query(ctx, allUsersQuery(pagePointer, pageSize), resp, nil)
+	// The key file may have been rotated without the change being noticed yet. The request is sent again only if
+	// the key read now differs from the rejected one.
+	reloaded, reloadErr := c.apiKey.reload()
+	if reloadErr != nil || reloaded == apiKey {
+		return statusCode, rawResp, err
+	}
+	return c.sendOnce(ctx, body, reloaded)
+}
+
+// sendOnce posts a marshalled query once with apiKey.
+func (c *ConnectorClient) sendOnce(ctx context.Context, body []byte, apiKey string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(), bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("twingate-client: error getting all users %w", err)
+		return 0, nil, err
 	}
-	pg := ""
-	if resp.Data.Users.Pagination.HasNextPage {
-		pg = resp.Data.Users.Pagination.EndCursor
+	req.Header["X-API-KEY"] = []string{apiKey}
+	req.Header["Content-Type"] = []string{"application/json"}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return 0, nil, &retryableError{class: classifyTransportError(err), timeout: isTimeout(err), err: err}
 	}
-	users := make([]*User, 0, len(resp.Data.Users.Edges))
-	for _, user := range resp.Data.Users.Edges {
-		users = append(users, user.User)
+	defer resp.Body.Close()
+	rawResp, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, &retryableError{class: classifyTransportError(err), timeout: isTimeout(err), err: err}
+	}
+	// Redact the key before the response can reach an error message, in case the API echoes it back.
+	rawResp = c.apiKey.redact(rawResp)
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return 0, nil, &retryableError{
+				class:      classifyStatusCode(resp.StatusCode),
+				retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+				err:        fmt.Errorf("%w: %s", ErrRateLimited, string(rawResp)),
+			}
+		}
+		if resp.StatusCode == http.StatusForbidden {
+			return 0, nil, fmt.Errorf("%w: %s", ErrPermissionDenied, string(rawResp))
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			return 0, nil, fmt.Errorf("%w: %s", ErrUnauthorized, string(rawResp))
+		}
+		return 0, nil, &retryableError{
+			class:   classifyStatusCode(resp.StatusCode),
+			timeout: resp.StatusCode == http.StatusGatewayTimeout,
+			err:     fmt.Errorf("twingate-client: GraphQL HTTP request failed %d %s", resp.StatusCode, string(rawResp)),
+		}
 	}
+	return resp.StatusCode, rawResp, nil
+}
 
-	rv := &UsersResponse{
-		Users:                users,
-		RateLimitDescription: rateLimitDescription,
-		Pagination:           pg,
+func graphQLError(gqlErrors []GraphQLError) error {
+	messages := make([]string, 0, len(gqlErrors))
+	for _, e := range gqlErrors {
+		messages = append(messages, e.Message)
 	}
-	return rv, nil
+	msg := strings.Join(messages, "; ")
+	if isPermissionMessage(msg) {
+		return fmt.Errorf("%w: %s", ErrPermissionDenied, msg)
+	}
+	return fmt.Errorf("twingate-client: GraphQL request failed: %s", msg)
 }
 
-func (c *ConnectorClient) ListGroups(ctx context.Context, pagination string, pageSize uint32) (*GroupResourcesResponse, error) {
-	var pagePointer *string = nil
-	if pagination != "" {
-		pagePointer = &pagination
+// isPermissionMessage reports whether a Twingate error message says the API key lacks the required permission.
+func isPermissionMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "permission") || strings.Contains(msg, "not authorized") ||
+		strings.Contains(msg, "unauthorized") || strings.Contains(msg, "forbidden")
+}
+
+// apiError converts the error of a failed mutation into a Go error. Whether the object of a failed mutation exists
+// is not guessed from the message; callers that need to know look it up again.
+func apiError(msg *string, fallback string) error {
+	if msg == nil {
+		return fmt.Errorf("twingate: api error: %s", fallback)
 	}
-	resp := &GroupsQueryResponse{}
-	rateLimitDescription, err := c.query(ctx, groupsQuery(pagePointer, pageSize), resp, nil)
-	if err != nil {
-		return nil, fmt.Errorf("twingate-client: error getting groups %w", err)
+	if isPermissionMessage(*msg) {
+		return fmt.Errorf("%w: '%s'", ErrPermissionDenied, *msg)
 	}
-	groups := make([]Group, 0, len(resp.Data.Groups.Edges))
-	for _, group := range resp.Data.Groups.Edges {
-		groups = append(groups, *group.Group)
+	return fmt.Errorf("twingate: api error: '%s'", *msg)
+}
+
+func (c *ConnectorClient) ListUsers(ctx context.Context, pagination string, pageSize uint32) (*UsersResponse, error) {
+	gql := c.graphql()
+	var resp *getUsersResponse
+	err := c.fetchPage(ctx, ConnectionUsers, pageSize, func(ctx context.Context, first int) error {
+		var err error
+		resp, err = getUsers(ctx, gql, pagination, first)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("twingate-client: error getting all users %w", err)
 	}
-	pg := ""
-	if resp.Data.Groups.Pagination.HasNextPage {
-		pg = resp.Data.Groups.Pagination.EndCursor
+	return newConnection(resp.Users.Edges, &resp.Users.PageInfo, gql.rateLimitDescription, func(edge getUsersUsersUserConnectionEdgesUserEdge) *User {
+		return &User{
+			ID:        edge.Node.Id,
+			Email:     edge.Node.Email,
+			FirstName: edge.Node.FirstName,
+			LastName:  edge.Node.LastName,
+			IsAdmin:   edge.Node.IsAdmin,
+			Role:      string(edge.Node.Role),
+			State:     string(edge.Node.State),
+			Type:      string(edge.Node.Type),
+			CreatedAt: edge.Node.CreatedAt,
+			UpdatedAt: edge.Node.UpdatedAt,
+		}
+	}), nil
+}
+
+func (c *ConnectorClient) ListGroups(ctx context.Context, pagination string, pageSize uint32) (*GroupResourcesResponse, error) {
+	gql := c.graphql()
+	var resp *getGroupsResponse
+	err := c.fetchPage(ctx, ConnectionGroups, pageSize, func(ctx context.Context, first int) error {
+		var err error
+		resp, err = getGroups(ctx, gql, pagination, first)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("twingate-client: error getting groups %w", err)
 	}
-	rv := &GroupResourcesResponse{
-		Groups:               groups,
-		RateLimitDescription: rateLimitDescription,
-		Pagination:           pg,
+	return newConnection(resp.Groups.Edges, &resp.Groups.PageInfo, gql.rateLimitDescription, func(edge getGroupsGroupsGroupConnectionEdgesGroupEdge) Group {
+		memberCount := edge.Node.Users.TotalCount
+		return Group{
+			ID:          edge.Node.Id,
+			Name:        edge.Node.Name,
+			IsActive:    edge.Node.IsActive,
+			Type:        string(edge.Node.Type),
+			CreatedAt:   edge.Node.CreatedAt,
+			UpdatedAt:   edge.Node.UpdatedAt,
+			MemberCount: &memberCount,
+		}
+	}), nil
+}
+
+// ListGroupsWithMembers lists groups together with up to membersPageSize of their members, which saves a members
+// query for every group that has no more members than that.
+func (c *ConnectorClient) ListGroupsWithMembers(ctx context.Context, pagination string, pageSize uint32, membersPageSize uint32) (*GroupsWithMembersResponse, error) {
+	gql := c.graphql()
+	var resp *getGroupsWithMembersResponse
+	err := c.fetchPage(ctx, ConnectionGroups, pageSize, func(ctx context.Context, first int) error {
+		var err error
+		resp, err = getGroupsWithMembers(ctx, gql, pagination, first, int(membersPageSize))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("twingate-client: error getting groups with members %w", err)
 	}
-	return rv, nil
+	return newConnection(resp.Groups.Edges, &resp.Groups.PageInfo, gql.rateLimitDescription, func(edge getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdge) GroupWithMembers {
+		node := edge.Node
+		members := newConnection(node.Users.Edges, &node.Users.PageInfo, nil, func(edge getGroupsWithMembersGroupsGroupConnectionEdgesGroupEdgeNodeGroupUsersUserConnectionEdgesUserEdge) string {
+			return edge.Node.Id
+		})
+		memberCount := node.Users.TotalCount
+		return GroupWithMembers{
+			Group: Group{
+				ID:          node.Id,
+				Name:        node.Name,
+				IsActive:    node.IsActive,
+				Type:        string(node.Type),
+				CreatedAt:   node.CreatedAt,
+				UpdatedAt:   node.UpdatedAt,
+				MemberCount: &memberCount,
+			},
+			MemberIDs:         members.Nodes,
+			MembersPagination: members.Pagination,
+		}
+	}), nil
 }
 
 func (c *ConnectorClient) ListRoles(ctx context.Context) ([]*Role, error) {
@@ -301,100 +540,318 @@ func (c *ConnectorClient) ListRoles(ctx context.Context) ([]*Role, error) {
 	return roles, nil
 }
 
-func (c *ConnectorClient) ListGroupGrants(ctx context.Context, groupID string) (*GroupGrantsResponse, error) {
-	resp := &GroupMembersQueryResponse{}
-	variable := map[string]string{"groupID": groupID}
-	rateLimitDescription, err := c.query(ctx, getGroupMembersQuery, resp, variable)
+func (c *ConnectorClient) ListGroupGrants(ctx context.Context, groupID string, pagination string, pageSize uint32) (*GroupGrantsResponse, error) {
+	gql := c.graphql()
+	var resp *getGroupMembersResponse
+	err := c.fetchPage(ctx, ConnectionGroupMembers, pageSize, func(ctx context.Context, first int) error {
+		var err error
+		resp, err = getGroupMembers(ctx, gql, groupID, pagination, first)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("twingate-client: error getting group members for %s: %w", c.Domain, err)
 	}
-	grants := make([]GroupGrant, 0, len(resp.Data.Group.Users.Edges))
-	for _, user := range resp.Data.Group.Users.Edges {
-		grants = append(grants, GroupGrant{
-			PrincipalID: user.User.ID,
+	return newConnection(resp.Group.Users.Edges, &resp.Group.Users.PageInfo, gql.rateLimitDescription, func(edge getGroupMembersGroupUsersUserConnectionEdgesUserEdge) GroupGrant {
+		return GroupGrant{
+			PrincipalID: edge.Node.Id,
 			GroupID:     groupID,
-		})
+		}
+	}), nil
+}
+
+// CreateUser creates a new Twingate user. When sendInvite is set Twingate emails the user an invitation.
+func (c *ConnectorClient) CreateUser(ctx context.Context, email string, firstName string, lastName string, role string, sendInvite bool) (*CreateUserResponse, error) {
+	gql := c.graphql()
+	resp, err := createUser(ctx, gql, email, firstName, lastName, UserRole(role), sendInvite)
+	if err != nil {
+		return nil, fmt.Errorf("twingate-client: error creating user for %s: %w", c.Domain, err)
 	}
-	rv := &GroupGrantsResponse{
-		Grants:               grants,
-		RateLimitDescription: rateLimitDescription,
+
+	if !resp.UserCreate.Ok || resp.UserCreate.Entity == nil {
+		if resp.UserCreate.Error != nil {
+			return nil, fmt.Errorf("twingate: api error: '%s'", *resp.UserCreate.Error)
+		}
+		return nil, fmt.Errorf("twingate: api error: unable to create user %s", email)
+	}
+
+	entity := resp.UserCreate.Entity
+	rv := &CreateUserResponse{
+		User: &User{
+			ID:        entity.Id,
+			Email:     entity.Email,
+			FirstName: entity.FirstName,
+			LastName:  entity.LastName,
+			IsAdmin:   entity.IsAdmin,
+			Role:      string(entity.Role),
+			State:     string(entity.State),
+			Type:      string(entity.Type),
+			CreatedAt: entity.CreatedAt,
+			UpdatedAt: entity.UpdatedAt,
+		},
+		RateLimitDescription: gql.rateLimitDescription,
 	}
 	return rv, nil
 }
 
-func (c *ConnectorClient) GrantGroupMembership(ctx context.Context, groupID string, userID string) (*GrantEntitlementResponse, error) {
-	resp := &GrantAndRevokeGroupResponse{}
-	rateLimitDescription, err := c.query(ctx, addGroupMemberQueryFormat(groupID, userID), resp, nil)
+// DisableUser sets the state of a Twingate user to DISABLED.
+func (c *ConnectorClient) DisableUser(ctx context.Context, userID string) (*UpdateUserStateResponse, error) {
+	return c.updateUserState(ctx, userID, UserStateUpdateInputDisabled)
+}
+
+// EnableUser sets the state of a Twingate user back to ACTIVE.
+func (c *ConnectorClient) EnableUser(ctx context.Context, userID string) (*UpdateUserStateResponse, error) {
+	return c.updateUserState(ctx, userID, UserStateUpdateInputActive)
+}
+
+func (c *ConnectorClient) updateUserState(ctx context.Context, userID string, state UserStateUpdateInput) (*UpdateUserStateResponse, error) {
+	gql := c.graphql()
+	resp, err := updateUserState(ctx, gql, userID, state)
 	if err != nil {
-		return nil, fmt.Errorf("twingate-client: error granting group member for %s: %w", c.Domain, err)
+		return nil, fmt.Errorf("twingate-client: error updating state of user %s for %s: %w", userID, c.Domain, err)
 	}
 
-	if !resp.Data.GroupUpdate.Ok {
-		if resp.Data.GroupUpdate.Error != nil {
-			return nil, fmt.Errorf("twingate: api error: '%s'", *resp.Data.GroupUpdate.Error)
+	if !resp.UserDetailsUpdate.Ok {
+		return nil, apiError(resp.UserDetailsUpdate.Error, fmt.Sprintf("unable to set state %s for user %s", state, userID))
+	}
+
+	rv := &UpdateUserStateResponse{
+		RateLimitDescription: gql.rateLimitDescription,
+	}
+	if entity := resp.UserDetailsUpdate.Entity; entity != nil {
+		rv.User = &User{
+			ID:        entity.Id,
+			Email:     entity.Email,
+			FirstName: entity.FirstName,
+			LastName:  entity.LastName,
+			IsAdmin:   entity.IsAdmin,
+			State:     string(entity.State),
 		}
-		return nil, fmt.Errorf("twingate: api error: unable to get group membership for group %s, and user %s", groupID, userID)
 	}
+	return rv, nil
+}
 
-	rv := &GrantEntitlementResponse{
-		RateLimitDescription: rateLimitDescription,
+// DeleteUser permanently deletes a Twingate user.
+func (c *ConnectorClient) DeleteUser(ctx context.Context, userID string) (*DeleteUserResponse, error) {
+	gql := c.graphql()
+	resp, err := deleteUser(ctx, gql, userID)
+	if err != nil {
+		return nil, fmt.Errorf("twingate-client: error deleting user %s for %s: %w", userID, c.Domain, err)
+	}
+
+	if !resp.UserDelete.Ok {
+		return nil, apiError(resp.UserDelete.Error, fmt.Sprintf("unable to delete user %s", userID))
+	}
+
+	rv := &DeleteUserResponse{
+		RateLimitDescription: gql.rateLimitDescription,
 	}
 	return rv, nil
 }
 
-func (c *ConnectorClient) RevokeGroupMembership(ctx context.Context, groupID string, userID string) (*RevokeEntitlementResponse, error) {
-	resp := &GrantAndRevokeGroupResponse{}
-	rateLimitDescription, err := c.query(ctx, removeGroupMemberQueryFormat(groupID, userID), resp, nil)
+// GetUser returns a single user, or an error wrapping ErrNotFound if it does not exist.
+func (c *ConnectorClient) GetUser(ctx context.Context, userID string) (*UserResponse, error) {
+	gql := c.graphql()
+	resp, err := getUserDetails(ctx, gql, userID)
 	if err != nil {
-		return nil, fmt.Errorf("twingate-client: error revoking group member for %s: %w", c.Domain, err)
+		return nil, fmt.Errorf("twingate-client: error getting user %s for %s: %w", userID, c.Domain, err)
+	}
+	if resp.User == nil {
+		return nil, fmt.Errorf("%w: user %s", ErrNotFound, userID)
+	}
+
+	rv := &UserResponse{
+		User: &User{
+			ID:        resp.User.Id,
+			Email:     resp.User.Email,
+			FirstName: resp.User.FirstName,
+			LastName:  resp.User.LastName,
+			IsAdmin:   resp.User.IsAdmin,
+			Role:      string(resp.User.Role),
+			State:     string(resp.User.State),
+			Type:      string(resp.User.Type),
+			CreatedAt: resp.User.CreatedAt,
+			UpdatedAt: resp.User.UpdatedAt,
+		},
+		RateLimitDescription: gql.rateLimitDescription,
 	}
-	if !resp.Data.GroupUpdate.Ok {
-		if resp.Data.GroupUpdate.Error != nil {
-			return nil, fmt.Errorf("twingate: api error: '%s'", *resp.Data.GroupUpdate.Error)
-		}
-		return nil, fmt.Errorf("twingate: api error: unable to revoke group membership for group %s, and user %s", groupID, userID)
+	return rv, nil
+}
+
+// GetGroup returns a single group, or an error wrapping ErrNotFound if it does not exist.
+func (c *ConnectorClient) GetGroup(ctx context.Context, groupID string) (*GroupResponse, error) {
+	gql := c.graphql()
+	resp, err := getGroupDetails(ctx, gql, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("twingate-client: error getting group %s for %s: %w", groupID, c.Domain, err)
+	}
+	if resp.Group == nil {
+		return nil, fmt.Errorf("%w: group %s", ErrNotFound, groupID)
 	}
 
-	rv := &RevokeEntitlementResponse{
-		RateLimitDescription: rateLimitDescription,
+	rv := &GroupResponse{
+		Group: &Group{
+			ID:       resp.Group.Id,
+			Name:     resp.Group.Name,
+			IsActive: resp.Group.IsActive,
+			Type:     string(resp.Group.Type),
+		},
+		RateLimitDescription: gql.rateLimitDescription,
+	}
+	return rv, nil
+}
+
+// CreateGroup creates a MANUAL group with an initial set of members and resources.
+func (c *ConnectorClient) CreateGroup(ctx context.Context, name string, userIDs []string, resourceIDs []string) (*GroupResponse, error) {
+	gql := c.graphql()
+	resp, err := createGroup(ctx, gql, name, userIDs, resourceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("twingate-client: error creating group %s for %s: %w", name, c.Domain, err)
+	}
+
+	if !resp.GroupCreate.Ok || resp.GroupCreate.Entity == nil {
+		return nil, apiError(resp.GroupCreate.Error, fmt.Sprintf("unable to create group %s", name))
+	}
+
+	entity := resp.GroupCreate.Entity
+	rv := &GroupResponse{
+		Group: &Group{
+			ID:       entity.Id,
+			Name:     entity.Name,
+			IsActive: entity.IsActive,
+			Type:     string(entity.Type),
+		},
+		RateLimitDescription: gql.rateLimitDescription,
 	}
 	return rv, nil
 }
 
-func (c *ConnectorClient) ListRoleGrants(ctx context.Context, roleID string, pagination string, pageSize uint32) (*RoleGrantsResponse, error) {
-	var pagePointer *string = nil
-	if pagination != "" {
-		pagePointer = &pagination
+func (c *ConnectorClient) RenameGroup(ctx context.Context, groupID string, name string) (*GroupMutationResponse, error) {
+	gql := c.graphql()
+	resp, err := renameGroup(ctx, gql, groupID, name)
+	if err != nil {
+		return nil, fmt.Errorf("twingate-client: error renaming group %s for %s: %w", groupID, c.Domain, err)
+	}
+
+	if !resp.GroupUpdate.Ok {
+		return nil, apiError(resp.GroupUpdate.Error, fmt.Sprintf("unable to rename group %s", groupID))
 	}
-	resp := &UsersQueryResponse{}
-	rateLimitDescription, err := c.query(ctx, allUsersQuery(pagePointer, pageSize), resp, nil)
+
+	rv := &GroupMutationResponse{
+		RateLimitDescription: gql.rateLimitDescription,
+	}
+	return rv, nil
+}
+
+func (c *ConnectorClient) DeleteGroup(ctx context.Context, groupID string) (*GroupMutationResponse, error) {
+	gql := c.graphql()
+	resp, err := deleteGroup(ctx, gql, groupID)
 	if err != nil {
-		return nil, fmt.Errorf("twingate-client: error getting role grants for %s: %w", c.Domain, err)
-	}
-	grants := make([]RoleGrant, 0, len(resp.Data.Users.Edges))
-	for _, user := range resp.Data.Users.Edges {
-		if roleID == "admin" && user.User.IsAdmin {
-			grants = append(grants, RoleGrant{
-				PrincipalID: user.User.ID,
-				RoleID:      roleID,
-			})
-		} else if roleID == "member" && !user.User.IsAdmin {
-			grants = append(grants, RoleGrant{
-				PrincipalID: user.User.ID,
-				RoleID:      roleID,
-			})
+		return nil, fmt.Errorf("twingate-client: error deleting group %s for %s: %w", groupID, c.Domain, err)
+	}
+
+	if !resp.GroupDelete.Ok {
+		return nil, apiError(resp.GroupDelete.Error, fmt.Sprintf("unable to delete group %s", groupID))
+	}
+
+	rv := &GroupMutationResponse{
+		RateLimitDescription: gql.rateLimitDescription,
+	}
+	return rv, nil
+}
+
+// UserGroupsPageSize is the number of groups fetched per page when checking the groups of a user.
+const UserGroupsPageSize = 100
+
+// ListUserGroups returns a page of the IDs of the groups of a user, or an error wrapping ErrNotFound if the user does
+// not exist.
+func (c *ConnectorClient) ListUserGroups(ctx context.Context, userID string, pagination string, pageSize uint32) (*Connection[string], error) {
+	gql := c.graphql()
+	resp, err := getUserGroups(ctx, gql, userID, pagination, int(pageSize))
+	if err != nil {
+		return nil, fmt.Errorf("twingate-client: error getting groups of user %s for %s: %w", userID, c.Domain, err)
+	}
+	if resp.User == nil {
+		return nil, fmt.Errorf("%w: user %s", ErrNotFound, userID)
+	}
+	return newConnection(resp.User.Groups.Edges, &resp.User.Groups.PageInfo, gql.rateLimitDescription, func(edge getUserGroupsUserGroupsGroupConnectionEdgesGroupEdge) string {
+		return edge.Node.Id
+	}), nil
+}
+
+var errMemberFound = errors.New("twingate-client: member found")
+
+// IsGroupMember reports whether a user is a member of a group, by looking for the group among the groups of the
+// user. Users are in far fewer groups than groups have members. It returns an error wrapping ErrNotFound if the user
+// does not exist.
+func (c *ConnectorClient) IsGroupMember(ctx context.Context, groupID string, userID string) (bool, *v2.RateLimitDescription, error) {
+	fetch := func(ctx context.Context, pagination string, pageSize uint32) (*Connection[string], error) {
+		return c.ListUserGroups(ctx, userID, pagination, pageSize)
+	}
+	rateLimitDescription, err := Each(ctx, fetch, UserGroupsPageSize, func(id string) error {
+		if id == groupID {
+			return errMemberFound
 		}
+		return nil
+	})
+	if errors.Is(err, errMemberFound) {
+		return true, rateLimitDescription, nil
 	}
-	pg := ""
-	if resp.Data.Users.Pagination.HasNextPage {
-		pg = resp.Data.Users.Pagination.EndCursor
+	return false, rateLimitDescription, err
+}
+
+// GrantGroupMembership adds a user to a group. A user that is already a member is reported with AlreadyExists and
+// nothing is changed. The change is sent together with the membership changes of concurrent calls. A change refused
+// because of the rate limit fails with an error wrapping ErrRateLimited. The rate limit description is returned
+// with errors as well.
+func (c *ConnectorClient) GrantGroupMembership(ctx context.Context, groupID string, userID string) (*GrantEntitlementResponse, error) {
+	member, rateLimitDescription, err := c.IsGroupMember(ctx, groupID, userID)
+	if err != nil {
+		return &GrantEntitlementResponse{RateLimitDescription: rateLimitDescription}, fmt.Errorf("twingate-client: error checking group member for %s: %w", c.Domain, err)
+	}
+	if member {
+		return &GrantEntitlementResponse{AlreadyExists: true, RateLimitDescription: rateLimitDescription}, nil
 	}
-	rv := &RoleGrantsResponse{
-		Grants:               grants,
+
+	result, rl, err := c.memberships.apply(ctx, groupID, userID, true)
+	if rl != nil {
+		rateLimitDescription = rl
+	}
+	if err != nil {
+		return &GrantEntitlementResponse{RateLimitDescription: rateLimitDescription}, fmt.Errorf("twingate-client: error granting group member for %s: %w", c.Domain, err)
+	}
+	rv := &GrantEntitlementResponse{
 		RateLimitDescription: rateLimitDescription,
-		Pagination:           pg,
 	}
-	return rv, nil
+	return rv, result.Err
+}
+
+// RevokeGroupMembership removes a user from a group. A user that is not a member, or that no longer exists, is
+// reported with AlreadyRevoked and nothing is changed. The change is sent together with the membership changes of
+// concurrent calls. A change refused because of the rate limit fails with an error wrapping ErrRateLimited. The rate
+// limit description is returned with errors as well.
+func (c *ConnectorClient) RevokeGroupMembership(ctx context.Context, groupID string, userID string) (*RevokeEntitlementResponse, error) {
+	member, rateLimitDescription, err := c.IsGroupMember(ctx, groupID, userID)
+	if errors.Is(err, ErrNotFound) {
+		return &RevokeEntitlementResponse{AlreadyRevoked: true, RateLimitDescription: rateLimitDescription}, nil
+	}
+	if err != nil {
+		return &RevokeEntitlementResponse{RateLimitDescription: rateLimitDescription}, fmt.Errorf("twingate-client: error checking group member for %s: %w", c.Domain, err)
+	}
+	if !member {
+		return &RevokeEntitlementResponse{AlreadyRevoked: true, RateLimitDescription: rateLimitDescription}, nil
+	}
+
+	result, rl, err := c.memberships.apply(ctx, groupID, userID, false)
+	if rl != nil {
+		rateLimitDescription = rl
+	}
+	if err != nil {
+		return &RevokeEntitlementResponse{RateLimitDescription: rateLimitDescription}, fmt.Errorf("twingate-client: error revoking group member for %s: %w", c.Domain, err)
+	}
+	rv := &RevokeEntitlementResponse{
+		RateLimitDescription: rateLimitDescription,
+	}
+	return rv, result.Err
 }
 
 // TODO(mstanbCO): Fix the rate limiting logic when it becomes an issue
@@ -404,6 +861,8 @@ func (c *ConnectorClient) getRateLimitDescription(ctx context.Context, isOverLim
 	now := time.Now().Unix()
 	// Round down to the nearest whole minute
 	currentBucket := now - (now % 60)
+	c.rateLimitMu.Lock()
+	defer c.rateLimitMu.Unlock()
 	if isOverLimit {
 		status = v2.RateLimitDescription_STATUS_OVERLIMIT
 		remaining = 0
@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+)
+
+const testAPIKey = "tgak-test-0123456789abcdefghijklmnopqrstuvwxyz"
+
+// testDataset returns a tenant with users users and one MANUAL group without members.
+func testDataset(users int) *twingatefake.Dataset {
+	data := &twingatefake.Dataset{
+		Groups: []*twingatefake.Group{{ID: "R3JvdXA6MQ==", Name: "team", IsActive: true, Type: "MANUAL"}},
+	}
+	for i := 0; i < users; i++ {
+		data.Users = append(data.Users, &twingatefake.User{
+			ID:    fmt.Sprintf("User:%d", i),
+			Email: fmt.Sprintf("user-%d@example.com", i),
+			State: "ACTIVE",
+		})
+	}
+	return data
+}
+
+// newTestClient returns a client of a fake Twingate serving data. Requests are not retried unless opts set a retry
+// policy.
+func newTestClient(t *testing.T, data *twingatefake.Dataset, opts ...Option) (*ConnectorClient, *twingatefake.Server) {
+	t.Helper()
+	fake := twingatefake.New(data, twingatefake.WithAPIKey(testAPIKey))
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	opts = append([]Option{WithAPIURL(server.URL + "/api/graphql/"), WithRetryPolicy(RetryPolicy{})}, opts...)
+	c, err := New(context.Background(), testAPIKey, "example", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, fake
+}
+
+// groupMembers returns the IDs of the members of groupID.
+func groupMembers(t *testing.T, c *ConnectorClient, groupID string) map[string]bool {
+	t.Helper()
+	rv := make(map[string]bool)
+	_, err := Each(context.Background(), func(ctx context.Context, pagination string, pageSize uint32) (*Connection[GroupGrant], error) {
+		return c.ListGroupGrants(ctx, groupID, pagination, pageSize)
+	}, 100, func(g GroupGrant) error {
+		rv[g.PrincipalID] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rv
+}
+
+func TestCreateUser(t *testing.T) {
+	ctx := context.Background()
+	c, fake := newTestClient(t, testDataset(1))
+
+	resp, err := c.CreateUser(ctx, "new@example.com", "New", "User", "MEMBER", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.User.Email != "new@example.com" || resp.User.Role != "MEMBER" || resp.User.State != "PENDING" {
+		t.Errorf("created user %+v, want a PENDING MEMBER with email new@example.com", resp.User)
+	}
+	created, err := c.GetUser(ctx, resp.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.User.FirstName != "New" || created.User.LastName != "User" {
+		t.Errorf("created user is named %q %q", created.User.FirstName, created.User.LastName)
+	}
+
+	if _, err := c.CreateUser(ctx, "user-0@example.com", "", "", "MEMBER", false); err == nil {
+		t.Error("creating a user with an existing email succeeded")
+	}
+	if calls := fake.Calls("createUser"); calls != 2 {
+		t.Errorf("sent createUser %d times, want 2", calls)
+	}
+}
+
+func TestUpdateUserState(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t, testDataset(1))
+
+	for _, step := range []struct {
+		update func(ctx context.Context, userID string) (*UpdateUserStateResponse, error)
+		want   string
+	}{
+		{c.DisableUser, "DISABLED"},
+		{c.EnableUser, "ACTIVE"},
+	} {
+		resp, err := step.update(ctx, "User:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.User.State != step.want {
+			t.Errorf("user state is %s, want %s", resp.User.State, step.want)
+		}
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t, testDataset(2))
+
+	if _, err := c.DeleteUser(ctx, "User:0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetUser(ctx, "User:0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("looking up the deleted user returned %v, want ErrNotFound", err)
+	}
+	if _, err := c.GetUser(ctx, "User:1"); err != nil {
+		t.Errorf("the other user is gone: %v", err)
+	}
+}
+
+func TestGroupLifecycle(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t, testDataset(2))
+
+	created, err := c.CreateGroup(ctx, "project-x", []string{"User:0", "User:1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Group.Name != "project-x" || created.Group.Type != string(GroupTypeManual) {
+		t.Errorf("created group %+v, want a MANUAL group named project-x", created.Group)
+	}
+	if members := groupMembers(t, c, created.Group.ID); len(members) != 2 {
+		t.Errorf("created group has %d members, want 2", len(members))
+	}
+
+	if _, err := c.RenameGroup(ctx, created.Group.ID, "project-y"); err != nil {
+		t.Fatal(err)
+	}
+	renamed, err := c.GetGroup(ctx, created.Group.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Group.Name != "project-y" {
+		t.Errorf("group is named %q after the rename", renamed.Group.Name)
+	}
+
+	if _, err := c.DeleteGroup(ctx, created.Group.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetGroup(ctx, created.Group.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("looking up the deleted group returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	for _, tc := range []struct {
+		domain string
+		want   string
+	}{
+		{"acme", "acme.twingate.com"},
+		{" Acme ", "acme.twingate.com"},
+		{"acme.twingate.com", "acme.twingate.com"},
+		{"acme.twingate.com.", "acme.twingate.com"},
+		{"acme.stg.opstg.com", "acme.stg.opstg.com"},
+		{"https://acme.twingate.com", "acme.twingate.com"},
+		{"https://acme.twingate.com/", "acme.twingate.com"},
+		{"HTTPS://ACME.twingate.com/", "acme.twingate.com"},
+		// Paths, other schemes and credentials belong in the api url.
+		{"https://acme.twingate.com/api/graphql/", ""},
+		{"https://acme.twingate.com/?q=1", ""},
+		{"https://user@acme.twingate.com", ""},
+		{"http://acme.twingate.com", ""},
+		{"acme.twingate.com/api", ""},
+		{"", ""},
+		{"https://", ""},
+	} {
+		got, err := NormalizeDomain(tc.domain)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("NormalizeDomain(%q) = %q, want an error", tc.domain, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("NormalizeDomain(%q) = %q, %v, want %q", tc.domain, got, err, tc.want)
+		}
+	}
+}
+
+func TestValidateAPIURL(t *testing.T) {
+	for apiURL, valid := range map[string]bool{
+		"https://acme.twingate.com/api/graphql/": true,
+		"http://127.0.0.1:8080/api/graphql/":     true,
+		"acme.twingate.com/api/graphql/":         false,
+		"/api/graphql/":                          false,
+		"ftp://acme.twingate.com/":               false,
+		"https://":                               false,
+		"https://acme.twingate.com/%zz":          false,
+	} {
+		if err := validateAPIURL(apiURL); (err == nil) != valid {
+			t.Errorf("validateAPIURL(%q) returned %v, want valid %v", apiURL, err, valid)
+		}
+	}
+}
+
+func TestNewRejectsInvalidAPIURL(t *testing.T) {
+	if _, err := New(context.Background(), testAPIKey, "", WithAPIURL("acme.twingate.com/api/graphql/")); err == nil {
+		t.Error("New accepted an api url without a scheme")
+	}
+}
@@ -2,12 +2,16 @@ package connector
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"strings"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-twingate/pkg/connector/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 )
 
 var (
@@ -32,58 +36,195 @@ var (
 )
 
 type Config struct {
+	// Domain is the Twingate network, as a name, hostname or https URL.
 	Domain string
 	ApiKey string
+	// APIKeyFile is read for the api key instead of ApiKey when set, and read again whenever it changes.
+	APIKeyFile string
+	// APIURL overrides the GraphQL endpoint derived from Domain when set.
+	APIURL string
+	// PrefetchGroupMembers fetches group members together with the groups list instead of with one query per group.
+	PrefetchGroupMembers bool
+	// IncrementalSync reuses the group members of the previous sync for groups that have not changed since.
+	IncrementalSync bool
+	// Provisioning is set when provisioning is enabled, so that Validate reports the write permissions it needs.
+	Provisioning bool
+	// RecordDir saves every API request and response to this directory when set.
+	RecordDir string
+	// ReplayDir serves API requests from the recordings in this directory instead of the Twingate API when set.
+	ReplayDir string
+	// PageSizes fixes the page size of some lists. The page size of the others adapts to how quickly Twingate
+	// answers.
+	PageSizes map[client.ConnectionType]uint32
+	// GroupFilter selects the groups that are synced.
+	GroupFilter GroupFilterConfig
+	// IdentityProvider names the identity provider that SYNCED users come from, such as Okta, for the user profile and
+	// error messages. The Twingate API does not say which one it is.
+	IdentityProvider string
+	// ResourceTypes are the IDs of the resource types that are synced. Empty syncs every resource type.
+	ResourceTypes []string
+	// Transport configures the proxy and certificates used to reach Twingate.
+	Transport client.TransportConfig
+	// Tenants syncs several Twingate networks under a tenant resource type when set, instead of Domain and the api
+	// key. The other options apply to every tenant.
+	Tenants []TenantConfig
 }
+
+// resourceTypes are all resource types the connector can sync, in the order their syncers run.
+var resourceTypes = []*v2.ResourceType{resourceTypeGroup, resourceTypeRole, resourceTypeUser}
+
+// ValidateResourceTypes returns an error when ids names a resource type the connector does not sync.
+func ValidateResourceTypes(ids []string) error {
+	_, err := selectResourceTypes(ids)
+	return err
+}
+
+// selectResourceTypes returns the set of resource type IDs in ids, or every resource type when ids is empty.
+func selectResourceTypes(ids []string) (map[string]bool, error) {
+	known := make(map[string]bool, len(resourceTypes))
+	names := make([]string, 0, len(resourceTypes))
+	rv := make(map[string]bool, len(resourceTypes))
+	for _, rt := range resourceTypes {
+		known[rt.Id] = true
+		names = append(names, rt.Id)
+		if len(ids) == 0 {
+			rv[rt.Id] = true
+		}
+	}
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if !known[id] {
+			return nil, fmt.Errorf("twingate: unknown resource type %q, expected one of %s", id, strings.Join(names, ", "))
+		}
+		rv[id] = true
+	}
+	return rv, nil
+}
+
 type Twingate struct {
-	client *client.ConnectorClient
-	domain string
-	apiKey string
+	client               *client.ConnectorClient
+	domain               string
+	prefetchGroupMembers bool
+	provisioning         bool
+	incrementalSync      bool
+	// groups is nil when every group is synced.
+	groups *groupFilter
+	// resourceTypes holds the IDs of the synced resource types.
+	resourceTypes    map[string]bool
+	identityProvider string
+	// tenants is only set in multi-tenant mode, where they sync everything but the tenant resources.
+	tenants []*tenant
 }
 
 func New(ctx context.Context, config Config) (*Twingate, error) {
-	client, err := client.New(ctx, config.ApiKey, config.Domain)
+	groups, err := newGroupFilter(config.GroupFilter)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := selectResourceTypes(config.ResourceTypes)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Tenants) == 0 && !selected[resourceTypeUser.Id] && (selected[resourceTypeGroup.Id] || selected[resourceTypeRole.Id]) {
+		ctxzap.Extract(ctx).Warn("twingate: users are not synced, so group and role grants refer to users missing from the sync")
+	}
+	if len(config.Tenants) > 0 {
+		tenants, err := newTenants(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return &Twingate{resourceTypes: selected, tenants: tenants}, nil
+	}
+	opts := []client.Option{client.WithTransport(config.Transport)}
+	if config.APIURL != "" {
+		opts = append(opts, client.WithAPIURL(config.APIURL))
+	}
+	if config.APIKeyFile != "" {
+		opts = append(opts, client.WithAPIKeyFile(config.APIKeyFile))
+	}
+	if config.RecordDir != "" {
+		opts = append(opts, client.WithRecordDir(config.RecordDir))
+	}
+	if config.ReplayDir != "" {
+		opts = append(opts, client.WithReplayDir(config.ReplayDir))
+	}
+	for conn, size := range config.PageSizes {
+		opts = append(opts, client.WithPageSize(conn, size))
+	}
+	client, err := client.New(ctx, config.ApiKey, config.Domain, opts...)
 	if err != nil {
 		return nil, err
 	}
 	rv := &Twingate{
-		domain: config.Domain,
-		apiKey: config.ApiKey,
-		client: client,
+		domain:               client.Domain,
+		client:               client,
+		prefetchGroupMembers: config.PrefetchGroupMembers,
+		provisioning:         config.Provisioning,
+		groups:               groups,
+		resourceTypes:        selected,
+		identityProvider:     config.IdentityProvider,
+		incrementalSync:      config.IncrementalSync,
 	}
 	return rv, nil
 }
 
+// Close releases the clients of the connector, or those of its tenants.
+func (c *Twingate) Close() error {
+	var errs []error
+	if c.client != nil {
+		errs = append(errs, c.client.Close())
+	}
+	for _, t := range c.tenants {
+		errs = append(errs, t.Close())
+	}
+	return errors.Join(errs...)
+}
+
 func (c *Twingate) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 	var annos annotations.Annotations
-	annos.Update(&v2.ExternalLink{
-		Url: c.domain,
-	})
+	if c.domain != "" {
+		annos.Update(&v2.ExternalLink{
+			Url: "https://" + c.domain,
+		})
+	}
+
+	// Listed in the order of the original description, "users, groups, and roles".
+	var synced []string
+	for _, rt := range []*v2.ResourceType{resourceTypeUser, resourceTypeGroup, resourceTypeRole} {
+		if c.resourceTypes[rt.Id] {
+			synced = append(synced, strings.ToLower(rt.DisplayName)+"s")
+		}
+	}
+
+	description := fmt.Sprintf("Connector syncing Twingate %s to Baton", joinList(synced))
+	if len(c.tenants) > 0 {
+		description = fmt.Sprintf("Connector syncing Twingate %s of %d tenants to Baton", joinList(synced), len(c.tenants))
+	}
 
 	return &v2.ConnectorMetadata{
 		DisplayName: "Twingate",
-		Description: "Connector syncing Twingate users, groups, and roles to Baton",
+		Description: description,
 		Annotations: annos,
 	}, nil
 }
 
-func (c *Twingate) Validate(ctx context.Context) (annotations.Annotations, error) {
-	_, err := c.client.ListUsers(ctx, "", 1)
-	if err != nil {
-		return nil, err
-	}
-
-	return nil, nil
-}
-
 func (c *Twingate) Asset(ctx context.Context, asset *v2.AssetRef) (string, io.ReadCloser, error) {
 	return "", nil, nil
 }
 
 func (c *Twingate) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
-	return []connectorbuilder.ResourceSyncer{
-		groupBuilder(c.client, c.domain),
-		roleBuilder(c.client, c.domain),
-		userBuilder(c.client, c.domain),
+	if len(c.tenants) > 0 {
+		return c.tenantResourceSyncers(ctx)
+	}
+	var rv []connectorbuilder.ResourceSyncer
+	if c.resourceTypes[resourceTypeGroup.Id] {
+		rv = append(rv, groupBuilder(c.client, c.domain, c.prefetchGroupMembers, c.incrementalSync, c.groups, c.identityProvider))
+	}
+	if c.resourceTypes[resourceTypeRole.Id] {
+		rv = append(rv, roleBuilder(c.client, c.domain))
+	}
+	if c.resourceTypes[resourceTypeUser.Id] {
+		rv = append(rv, userBuilder(c.client, c.domain, c.identityProvider))
 	}
+	return rv
 }
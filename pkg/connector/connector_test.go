@@ -0,0 +1,54 @@
+package connector
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+)
+
+// testAPIKey is long enough that redacting it cannot change anything but the key.
+const testAPIKey = "tgak-test-0123456789abcdefghijklmnopqrstuvwxyz"
+
+// IDs of the users and groups in ../twingatefake/testdata/tenant.json.
+const (
+	adaID      = "VXNlcjox"     // MANUAL admin, member of Everyone and team-platform
+	graceID    = "VXNlcjoy"     // MANUAL DEVOPS, member of all groups
+	alanID     = "VXNlcjoz"     // SYNCED and DISABLED, member of Everyone and Engineering
+	invitedID  = "VXNlcjo0"     // MANUAL, PENDING invitation
+	everyoneID = "R3JvdXA6MQ==" // SYSTEM
+	platformID = "R3JvdXA6Mg==" // MANUAL
+	engID      = "R3JvdXA6Mw==" // SYNCED
+)
+
+// rateLimitWait is the Retry-After of rate limit faults. It is longer than the client retries for, so that rate
+// limited requests fail at once.
+const rateLimitWait = 2 * time.Minute
+
+func testDataset(t *testing.T) *twingatefake.Dataset {
+	t.Helper()
+	data, err := twingatefake.LoadDataset("../twingatefake/testdata/tenant.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// newTestConnector returns a connector configured by config that talks to a fake Twingate serving the test dataset.
+func newTestConnector(t *testing.T, config Config, opts ...twingatefake.Option) (*Twingate, *twingatefake.Server) {
+	t.Helper()
+	fake := twingatefake.New(testDataset(t), append([]twingatefake.Option{twingatefake.WithAPIKey(testAPIKey)}, opts...)...)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	config.Domain = "example"
+	config.ApiKey = testAPIKey
+	config.APIURL = server.URL + "/api/graphql/"
+	tg, err := New(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tg, fake
+}
@@ -0,0 +1,133 @@
+package connector
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/types"
+	"github.com/conductorone/baton-twingate/pkg/connector/client"
+)
+
+// testPageSizes are small enough that every listing of the test dataset takes several pages.
+var testPageSizes = map[client.ConnectionType]uint32{
+	client.ConnectionUsers:        2,
+	client.ConnectionGroups:       1,
+	client.ConnectionGroupMembers: 1,
+}
+
+// syncedGrants lists every resource and its grants through the connector server the way a sync does, and returns
+// the grants by the ID of their principal and entitlement.
+func syncedGrants(t *testing.T, server types.ConnectorServer) map[string]*v2.Grant {
+	t.Helper()
+	ctx := context.Background()
+	resourceTypes, err := server.ListResourceTypes(ctx, &v2.ResourceTypesServiceListResourceTypesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grants := make(map[string]*v2.Grant)
+	for _, rt := range resourceTypes.List {
+		var resources []*v2.Resource
+		pageToken := ""
+		for {
+			resp, err := server.ListResources(ctx, &v2.ResourcesServiceListResourcesRequest{ResourceTypeId: rt.Id, PageToken: pageToken})
+			if err != nil {
+				t.Fatalf("listing %s: %v", rt.Id, err)
+			}
+			resources = append(resources, resp.List...)
+			if pageToken = resp.NextPageToken; pageToken == "" {
+				break
+			}
+		}
+		for _, r := range resources {
+			for {
+				resp, err := server.ListGrants(ctx, &v2.GrantsServiceListGrantsRequest{Resource: r, PageToken: pageToken})
+				if err != nil {
+					t.Fatalf("listing the grants of %s %s: %v", rt.Id, r.Id.Resource, err)
+				}
+				for _, g := range resp.List {
+					grants[g.Principal.Id.Resource+" "+g.Entitlement.Id] = g
+				}
+				if pageToken = resp.NextPageToken; pageToken == "" {
+					break
+				}
+			}
+		}
+	}
+	return grants
+}
+
+func grantKeys(grants map[string]*v2.Grant) []string {
+	keys := make([]string, 0, len(grants))
+	for k := range grants {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestEndToEnd(t *testing.T) {
+	ctx := context.Background()
+	tg, fake := newTestConnector(t, Config{PageSizes: testPageSizes})
+	server, err := connectorbuilder.NewConnector(ctx, tg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Validate(ctx, &v2.ConnectorServiceValidateRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	mutations := fake.Calls("updateGroupMembers")
+
+	grants := syncedGrants(t, server)
+	platformMember := "group:" + platformID + ":" + groupMemberEntitlement
+	engMember := "group:" + engID + ":" + groupMemberEntitlement
+	for _, key := range []string{
+		adaID + " " + platformMember,
+		graceID + " " + platformMember,
+		graceID + " " + engMember,
+		alanID + " " + engMember,
+	} {
+		if grants[key] == nil {
+			t.Errorf("sync is missing grant %q, got %v", key, grantKeys(grants))
+		}
+	}
+	if grants[invitedID+" "+platformMember] != nil {
+		t.Errorf("sync has a grant of team-platform to a user outside it")
+	}
+
+	entitlement := grants[adaID+" "+platformMember].Entitlement
+	_, err = server.Grant(ctx, &v2.GrantManagerServiceGrantRequest{
+		Principal:   &v2.Resource{Id: userID(invitedID)},
+		Entitlement: entitlement,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	grants = syncedGrants(t, server)
+	granted := grants[invitedID+" "+platformMember]
+	if granted == nil {
+		t.Fatalf("sync after the grant is missing it, got %v", grantKeys(grants))
+	}
+
+	if _, err := server.Revoke(ctx, &v2.GrantManagerServiceRevokeRequest{Grant: granted}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Revoke(ctx, &v2.GrantManagerServiceRevokeRequest{Grant: grants[adaID+" "+platformMember]}); err != nil {
+		t.Fatal(err)
+	}
+	grants = syncedGrants(t, server)
+	for _, id := range []string{invitedID, adaID} {
+		if grants[id+" "+platformMember] != nil {
+			t.Errorf("sync after the revoke still has the membership of %s", id)
+		}
+	}
+	if grants[graceID+" "+platformMember] == nil {
+		t.Error("revoking other members removed the membership of Grace")
+	}
+	if calls := fake.Calls("updateGroupMembers") - mutations; calls != 3 {
+		t.Errorf("sent %d membership mutations, want 3", calls)
+	}
+}
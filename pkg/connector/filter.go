@@ -0,0 +1,113 @@
+package connector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/conductorone/baton-twingate/pkg/connector/client"
+)
+
+// GroupFilterConfig selects the groups that are synced. Empty fields select every group.
+type GroupFilterConfig struct {
+	// Include keeps only groups whose name matches this regular expression.
+	Include string
+	// Exclude drops groups whose name matches this regular expression.
+	Exclude string
+	// IDs keeps only the groups with these IDs.
+	IDs []string
+	// ExcludeIDs drops the groups with these IDs.
+	ExcludeIDs []string
+	// Types keeps only groups of these types: manual, synced or system.
+	Types []string
+}
+
+// groupFilter is a compiled GroupFilterConfig.
+type groupFilter struct {
+	include    *regexp.Regexp
+	exclude    *regexp.Regexp
+	ids        map[string]bool
+	excludeIDs map[string]bool
+	types      map[client.GroupType]bool
+}
+
+// Validate returns an error when a pattern or group type of the config is invalid.
+func (c GroupFilterConfig) Validate() error {
+	_, err := newGroupFilter(c)
+	return err
+}
+
+// newGroupFilter checks and compiles config. It returns nil when config selects every group.
+func newGroupFilter(config GroupFilterConfig) (*groupFilter, error) {
+	f := &groupFilter{}
+	empty := true
+	var err error
+	if config.Include != "" {
+		empty = false
+		if f.include, err = regexp.Compile(config.Include); err != nil {
+			return nil, fmt.Errorf("twingate: invalid group include pattern: %w", err)
+		}
+	}
+	if config.Exclude != "" {
+		empty = false
+		if f.exclude, err = regexp.Compile(config.Exclude); err != nil {
+			return nil, fmt.Errorf("twingate: invalid group exclude pattern: %w", err)
+		}
+	}
+	if len(config.IDs) > 0 {
+		empty = false
+		f.ids = stringSet(config.IDs)
+	}
+	if len(config.ExcludeIDs) > 0 {
+		empty = false
+		f.excludeIDs = stringSet(config.ExcludeIDs)
+	}
+	if len(config.Types) > 0 {
+		empty = false
+		f.types = make(map[client.GroupType]bool, len(config.Types))
+		for _, t := range config.Types {
+			groupType := client.GroupType(strings.ToUpper(strings.TrimSpace(t)))
+			switch groupType {
+			case client.GroupTypeManual, client.GroupTypeSynced, client.GroupTypeSystem:
+				f.types[groupType] = true
+			default:
+				return nil, fmt.Errorf("twingate: unknown group type %q, expected manual, synced or system", t)
+			}
+		}
+	}
+	if empty {
+		return nil, nil
+	}
+	return f, nil
+}
+
+func stringSet(values []string) map[string]bool {
+	rv := make(map[string]bool, len(values))
+	for _, v := range values {
+		rv[strings.TrimSpace(v)] = true
+	}
+	return rv
+}
+
+// match reports whether group is synced. A nil filter matches every group.
+func (f *groupFilter) match(group client.Group) bool {
+	if f == nil {
+		return true
+	}
+	if f.ids != nil && !f.ids[group.ID] {
+		return false
+	}
+	if f.excludeIDs[group.ID] {
+		return false
+	}
+	if f.types != nil && !f.types[client.GroupType(group.Type)] {
+		return false
+	}
+	if f.include != nil && !f.include.MatchString(group.Name) {
+		return false
+	}
+	if f.exclude != nil && f.exclude.MatchString(group.Name) {
+		return false
+	}
+	return true
+}
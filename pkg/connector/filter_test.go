@@ -0,0 +1,107 @@
+package connector
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-twingate/pkg/connector/client"
+)
+
+// filterGroups are the groups of the test dataset.
+var filterGroups = []client.Group{
+	{ID: everyoneID, Name: "Everyone", Type: string(client.GroupTypeSystem)},
+	{ID: platformID, Name: "team-platform", Type: string(client.GroupTypeManual)},
+	{ID: engID, Name: "Engineering", Type: string(client.GroupTypeSynced)},
+}
+
+func TestGroupFilterMatch(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config GroupFilterConfig
+		want   []string
+	}{
+		{"no filter", GroupFilterConfig{}, []string{everyoneID, platformID, engID}},
+		{"include pattern", GroupFilterConfig{Include: "^team-"}, []string{platformID}},
+		{"exclude pattern", GroupFilterConfig{Exclude: "^team-"}, []string{everyoneID, engID}},
+		{"patterns are case sensitive", GroupFilterConfig{Include: "^e"}, nil},
+		{"include and exclude pattern", GroupFilterConfig{Include: "e", Exclude: "^team-"}, []string{everyoneID, engID}},
+		{"ids", GroupFilterConfig{IDs: []string{platformID, " " + engID + " "}}, []string{platformID, engID}},
+		{"excluded ids", GroupFilterConfig{ExcludeIDs: []string{everyoneID}}, []string{platformID, engID}},
+		{"ids and excluded ids", GroupFilterConfig{IDs: []string{platformID, engID}, ExcludeIDs: []string{engID}}, []string{platformID}},
+		{"type", GroupFilterConfig{Types: []string{"system"}}, []string{everyoneID}},
+		{"types", GroupFilterConfig{Types: []string{"Manual", " SYNCED"}}, []string{platformID, engID}},
+		{"every filter must match", GroupFilterConfig{Include: "ng", Types: []string{"manual"}}, nil},
+	} {
+		f, err := newGroupFilter(tc.config)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		var got []string
+		for _, g := range filterGroups {
+			if f.match(g) {
+				got = append(got, g.ID)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: matched %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestGroupFilterRejectsInvalidConfig(t *testing.T) {
+	for name, config := range map[string]GroupFilterConfig{
+		"include pattern": {Include: "("},
+		"exclude pattern": {Exclude: "[a-"},
+		"type":            {Types: []string{"manual", "dynamic"}},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("invalid %s was accepted", name)
+		}
+	}
+}
+
+func TestFilteredGroupsAreNotSynced(t *testing.T) {
+	ctx := context.Background()
+	for _, prefetch := range []bool{false, true} {
+		tg, _ := newTestConnector(t, Config{PrefetchGroupMembers: prefetch, GroupFilter: GroupFilterConfig{Exclude: "^team-"}})
+		groups := groupBuilder(tg.client, tg.domain, prefetch, false, tg.groups, "")
+
+		resources, _, _, err := groups.List(ctx, nil, &pagination.Token{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var listed []string
+		var memberships []string
+		for _, r := range resources {
+			listed = append(listed, r.Id.Resource)
+			grants, _, _, err := groups.Grants(ctx, r, &pagination.Token{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, g := range grants {
+				memberships = append(memberships, g.Entitlement.Resource.Id.Resource+"/"+g.Principal.Id.Resource)
+			}
+		}
+		sort.Strings(memberships)
+
+		if want := []string{everyoneID, engID}; !reflect.DeepEqual(listed, want) {
+			t.Errorf("prefetch %v: listed groups %v, want %v", prefetch, listed, want)
+		}
+		// Ada and Grace lose their team-platform memberships and keep the others.
+		want := []string{
+			engID + "/" + alanID, engID + "/" + graceID,
+			everyoneID + "/" + adaID, everyoneID + "/" + alanID, everyoneID + "/" + graceID, everyoneID + "/" + invitedID,
+		}
+		sort.Strings(want)
+		if !reflect.DeepEqual(memberships, want) {
+			t.Errorf("prefetch %v: got memberships %v, want %v", prefetch, memberships, want)
+		}
+		if _, ok := groups.members.take(platformID); ok {
+			t.Errorf("prefetch %v: the members of the filtered group were kept", prefetch)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package connector
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
@@ -11,6 +13,8 @@ import (
 	grant "github.com/conductorone/baton-sdk/pkg/types/grant"
 	res "github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/conductorone/baton-twingate/pkg/connector/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 )
 
 const (
@@ -18,9 +22,54 @@ const (
 )
 
 type groupResourceType struct {
-	resourceType *v2.ResourceType
-	domain       string
-	client       *client.ConnectorClient
+	resourceType    *v2.ResourceType
+	domain          string
+	client          *client.ConnectorClient
+	prefetchMembers bool
+	members         *groupMembersCache
+	// incrementalSync makes Grants skip the members of groups that did not change since the previous sync.
+	incrementalSync bool
+	// filter selects the synced groups. Groups it drops are never listed, so neither are their grants.
+	filter *groupFilter
+	// identityProvider names the identity provider that SYNCED groups come from, if configured.
+	identityProvider string
+}
+
+type cachedGroupMembers struct {
+	memberIDs  []string
+	pagination string
+}
+
+// groupMembersCache holds the members fetched together with the groups list until the grants of each group are
+// listed.
+type groupMembersCache struct {
+	mu     sync.Mutex
+	groups map[string]cachedGroupMembers
+}
+
+func newGroupMembersCache() *groupMembersCache {
+	return &groupMembersCache{groups: make(map[string]cachedGroupMembers)}
+}
+
+func (c *groupMembersCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.groups = make(map[string]cachedGroupMembers)
+}
+
+func (c *groupMembersCache) put(groupID string, members cachedGroupMembers) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.groups[groupID] = members
+}
+
+// take returns the cached members of a group and removes them from the cache.
+func (c *groupMembersCache) take(groupID string) (cachedGroupMembers, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	members, ok := c.groups[groupID]
+	delete(c.groups, groupID)
+	return members, ok
 }
 
 func (o *groupResourceType) ResourceType(_ context.Context) *v2.ResourceType {
@@ -31,6 +80,12 @@ func groupResource(ctx context.Context, group client.Group) (*v2.Resource, error
 	profile := map[string]interface{}{
 		"group_id":   group.ID,
 		"group_name": group.Name,
+		"group_type": group.Type,
+		"created_at": group.CreatedAt,
+		"updated_at": group.UpdatedAt,
+	}
+	if group.MemberCount != nil {
+		profile["member_count"] = *group.MemberCount
 	}
 
 	groupTraitOptions := []res.GroupTraitOption{
@@ -61,13 +116,21 @@ func (o *groupResourceType) List(ctx context.Context, resourceId *v2.ResourceId,
 			ResourceTypeID: resourceTypeGroup.Id,
 		})
 	}
-	resp, err := o.client.ListGroups(ctx, bag.PageToken(), ResourcesPageSize)
+	if o.prefetchMembers {
+		return o.listWithMembers(ctx, bag)
+	}
+
+	cursor, pageSize := parseCursorToken(bag.PageToken())
+	resp, err := o.client.ListGroups(ctx, cursor, o.client.PageSize(client.ConnectionGroups, pageSize))
 	if err != nil {
-		return nil, "", nil, err
+		return nil, "", rateLimitAnnotations(err), err
 	}
 
-	rv := make([]*v2.Resource, 0, len(resp.Groups))
-	for _, g := range resp.Groups {
+	rv := make([]*v2.Resource, 0, len(resp.Nodes))
+	for _, g := range resp.Nodes {
+		if !o.filter.match(g) {
+			continue
+		}
 		groupCopy := g
 		gr, err := groupResource(ctx, groupCopy)
 		if err != nil {
@@ -76,7 +139,46 @@ func (o *groupResourceType) List(ctx context.Context, resourceId *v2.ResourceId,
 
 		rv = append(rv, gr)
 	}
-	nextPage, err := bag.NextToken(resp.Pagination)
+	nextPage, err := bag.NextToken(cursorToken(resp.Pagination, o.client.PageSize(client.ConnectionGroups, 0)))
+	if err != nil {
+		return nil, "", nil, err
+	}
+	annotations := annotations.Annotations{}
+	if resp.RateLimitDescription != nil {
+		annotations.WithRateLimiting(resp.RateLimitDescription)
+	}
+	return rv, nextPage, annotations, nil
+}
+
+// listWithMembers lists groups together with their first page of members, and caches the members for Grants.
+func (o *groupResourceType) listWithMembers(ctx context.Context, bag *pagination.Bag) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if bag.PageToken() == "" {
+		o.members.reset()
+	}
+
+	cursor, pageSize := parseCursorToken(bag.PageToken())
+	resp, err := o.client.ListGroupsWithMembers(ctx, cursor, o.client.PageSize(client.ConnectionGroups, pageSize), NestedMembersPageSize)
+	if err != nil {
+		return nil, "", rateLimitAnnotations(err), err
+	}
+
+	rv := make([]*v2.Resource, 0, len(resp.Nodes))
+	for _, g := range resp.Nodes {
+		if !o.filter.match(g.Group) {
+			continue
+		}
+		gr, err := groupResource(ctx, g.Group)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		o.members.put(g.ID, cachedGroupMembers{
+			memberIDs:  g.MemberIDs,
+			pagination: g.MembersPagination,
+		})
+
+		rv = append(rv, gr)
+	}
+	nextPage, err := bag.NextToken(cursorToken(resp.Pagination, o.client.PageSize(client.ConnectionGroups, 0)))
 	if err != nil {
 		return nil, "", nil, err
 	}
@@ -102,32 +204,213 @@ func (o *groupResourceType) Entitlements(ctx context.Context, resource *v2.Resou
 }
 
 func (o *groupResourceType) Grants(ctx context.Context, resource *v2.Resource, pt *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
-	resp, err := o.client.ListGroupGrants(ctx, resource.Id.Resource)
+	bag := &pagination.Bag{}
+	err := bag.Unmarshal(pt.Token)
 	if err != nil {
 		return nil, "", nil, err
 	}
-	var rv []*v2.Grant
-	for _, groupGrant := range resp.Grants {
+	if bag.Current() == nil {
+		bag.Push(pagination.PageState{
+			ResourceTypeID: resource.Id.ResourceType,
+			ResourceID:     resource.Id.Resource,
+		})
+	}
+
+	etag := o.membersETag(resource)
+	if etag != nil && bag.PageToken() == "" {
+		matched, err := previousETagMatches(resource, etag)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if matched {
+			// The group did not change since the previous sync, so the SDK copies the member grants of the
+			// previous sync.
+			o.members.take(resource.Id.Resource)
+			annotations := annotations.Annotations{}
+			annotations.Update(&v2.ETagMatch{EntitlementId: etag.EntitlementId})
+			return nil, "", annotations, nil
+		}
+	}
+
+	var memberIDs []string
+	var pageToken string
+	var rateLimitDescription *v2.RateLimitDescription
+	var cached cachedGroupMembers
+	var ok bool
+	if bag.PageToken() == "" {
+		cached, ok = o.members.take(resource.Id.Resource)
+	}
+	if ok {
+		// Members fetched with the groups list. Only groups with more members than fit in the nested page
+		// continue below with the per-group members query.
+		memberIDs = cached.memberIDs
+		pageToken = cached.pagination
+	} else {
+		cursor, pageSize := parseCursorToken(bag.PageToken())
+		resp, err := o.client.ListGroupGrants(ctx, resource.Id.Resource, cursor, o.client.PageSize(client.ConnectionGroupMembers, pageSize))
+		if err != nil {
+			return nil, "", rateLimitAnnotations(err), err
+		}
+		for _, groupGrant := range resp.Nodes {
+			memberIDs = append(memberIDs, groupGrant.PrincipalID)
+		}
+		pageToken = resp.Pagination
+		rateLimitDescription = resp.RateLimitDescription
+	}
+
+	rv := make([]*v2.Grant, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
 		rv = append(rv, grant.NewGrant(
 			resource,
 			groupMemberEntitlement,
 			&v2.ResourceId{
 				ResourceType: resourceTypeUser.Id,
-				Resource:     groupGrant.PrincipalID,
+				Resource:     memberID,
 			},
 		))
 	}
+	nextPage, err := bag.NextToken(cursorToken(pageToken, o.client.PageSize(client.ConnectionGroupMembers, 0)))
+	if err != nil {
+		return nil, "", nil, err
+	}
 	annotations := annotations.Annotations{}
-	if resp.RateLimitDescription != nil {
+	if rateLimitDescription != nil {
+		annotations.WithRateLimiting(rateLimitDescription)
+	}
+	if etag != nil {
+		annotations.Update(etag)
+	}
+	return rv, nextPage, annotations, nil
+}
+
+// membersETag returns the ETag of the member grants of a group, built from the group's updatedAt and its number of
+// members. Twingate does not change the updatedAt of a group when one of its members is deleted, but the number of
+// members goes down. It returns nil when incremental sync is disabled or when the group has no updatedAt or member
+// count, in which case the members are always listed.
+func (o *groupResourceType) membersETag(resource *v2.Resource) *v2.ETag {
+	if !o.incrementalSync {
+		return nil
+	}
+
+	groupTrait := &v2.GroupTrait{}
+	annos := annotations.Annotations(resource.Annotations)
+	ok, err := annos.Pick(groupTrait)
+	if err != nil || !ok {
+		return nil
+	}
+	updatedAt, ok := res.GetProfileStringValue(groupTrait.GetProfile(), "updated_at")
+	if !ok || updatedAt == "" {
+		return nil
+	}
+	memberCount, ok := res.GetProfileInt64Value(groupTrait.GetProfile(), "member_count")
+	if !ok {
+		return nil
+	}
+
+	return &v2.ETag{
+		Value:         fmt.Sprintf("%s/%d", updatedAt, memberCount),
+		EntitlementId: ent.NewEntitlementID(resource, groupMemberEntitlement),
+	}
+}
+
+// previousETagMatches reports whether the resource carries the ETag of the previous sync and it equals etag.
+func previousETagMatches(resource *v2.Resource, etag *v2.ETag) (bool, error) {
+	prevETag := &v2.ETag{}
+	annos := annotations.Annotations(resource.Annotations)
+	ok, err := annos.Pick(prevETag)
+	if err != nil {
+		return false, err
+	}
+	return ok && prevETag.Value == etag.Value && prevETag.EntitlementId == etag.EntitlementId, nil
+}
+
+// Grant adds a user to a group. Adding a user that is already a member succeeds, so retried grants are safe. It is only
+// logged, since baton-sdk v0.1.7 has no annotation for a grant that already exists. The members of SYNCED groups are
+// owned by the identity provider and are refused. SYNCED users can be added to MANUAL groups, whose members the
+// identity provider leaves alone.
+func (o *groupResourceType) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	if principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("twingate: only users can be granted group membership, got %s", principal.Id.ResourceType)
+	}
+
+	groupID := entitlement.Resource.Id.Resource
+	if err := o.checkMembersChangeable(ctx, groupID, "granted"); err != nil {
+		return nil, err
+	}
+	resp, err := o.client.GrantGroupMembership(ctx, groupID, principal.Id.Resource)
+	annotations := annotations.Annotations{}
+	if resp != nil && resp.RateLimitDescription != nil {
+		annotations.WithRateLimiting(resp.RateLimitDescription)
+	}
+	if err != nil {
+		// A change refused because of the rate limit keeps the rate limit description, so that it is retried later.
+		return annotations, err
+	}
+	if resp.AlreadyExists {
+		l.Info("twingate: user is already a member of the group", zap.String("group_id", groupID), zap.String("user_id", principal.Id.Resource))
+	}
+	return annotations, nil
+}
+
+// Revoke removes a user from a group. Removing a user that is not a member, or from a group that was deleted, succeeds,
+// so retried revokes are safe. It is only logged, since baton-sdk v0.1.7 has no annotation for a grant that was
+// already revoked. The members of SYNCED groups are owned by the identity provider and are refused.
+func (o *groupResourceType) Revoke(ctx context.Context, g *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	principal := g.Principal
+	if principal.Id.ResourceType != resourceTypeUser.Id {
+		return nil, fmt.Errorf("twingate: only users can have group membership revoked, got %s", principal.Id.ResourceType)
+	}
+
+	groupID := g.Entitlement.Resource.Id.Resource
+	if err := o.checkMembersChangeable(ctx, groupID, "revoked"); err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			// Nobody is a member of a deleted group, so the grant is already revoked.
+			l.Info("twingate: group no longer exists", zap.String("group_id", groupID), zap.String("user_id", principal.Id.Resource))
+			return nil, nil
+		}
+		return nil, err
+	}
+	resp, err := o.client.RevokeGroupMembership(ctx, groupID, principal.Id.Resource)
+	annotations := annotations.Annotations{}
+	if resp != nil && resp.RateLimitDescription != nil {
 		annotations.WithRateLimiting(resp.RateLimitDescription)
 	}
-	return rv, "", annotations, nil
+	if err != nil {
+		// A change refused because of the rate limit keeps the rate limit description, so that it is retried later.
+		return annotations, err
+	}
+	if resp.AlreadyRevoked {
+		l.Info("twingate: user is not a member of the group", zap.String("group_id", groupID), zap.String("user_id", principal.Id.Resource))
+	}
+	return annotations, nil
+}
+
+// checkMembersChangeable returns an error when the members of a group cannot be changed in Twingate because the group
+// is SYNCED, since the identity provider would overwrite the change on its next sync.
+func (o *groupResourceType) checkMembersChangeable(ctx context.Context, groupID string, action string) error {
+	group, err := o.client.GetGroup(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if group.Group.Type == string(client.GroupTypeSynced) {
+		identityProvider := identityProviderName(o.identityProvider)
+		return fmt.Errorf("twingate: group %s (%s) is synced from %s and its membership cannot be %s in Twingate, change it in %s instead",
+			group.Group.ID, group.Group.Name, identityProvider, action, identityProvider)
+	}
+	return nil
 }
 
-func groupBuilder(client *client.ConnectorClient, domain string) *groupResourceType {
+func groupBuilder(client *client.ConnectorClient, domain string, prefetchMembers bool, incrementalSync bool, filter *groupFilter, identityProvider string) *groupResourceType {
 	return &groupResourceType{
-		resourceType: resourceTypeGroup,
-		domain:       domain,
-		client:       client,
+		resourceType:     resourceTypeGroup,
+		domain:           domain,
+		client:           client,
+		prefetchMembers:  prefetchMembers,
+		members:          newGroupMembersCache(),
+		incrementalSync:  incrementalSync,
+		filter:           filter,
+		identityProvider: identityProvider,
 	}
 }
@@ -0,0 +1,276 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-twingate/pkg/connector/client"
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func groupID(id string) *v2.ResourceId {
+	return &v2.ResourceId{ResourceType: resourceTypeGroup.Id, Resource: id}
+}
+
+func groupMemberEntitlementOf(id string) *v2.Entitlement {
+	r := &v2.Resource{Id: groupID(id)}
+	return &v2.Entitlement{Id: "group:" + id + ":" + groupMemberEntitlement, Resource: r}
+}
+
+func userPrincipal(id string) *v2.Resource {
+	return &v2.Resource{Id: userID(id)}
+}
+
+func TestGrantAndRevokeAreIdempotent(t *testing.T) {
+	ctx := context.Background()
+	tg, fake := newTestConnector(t, Config{})
+	groups := groupBuilder(tg.client, tg.domain, false, false, nil, "")
+	entitlement := groupMemberEntitlementOf(platformID)
+
+	// Ada is already a member, and granting it again changes nothing.
+	if _, err := groups.Grant(ctx, userPrincipal(adaID), entitlement); err != nil {
+		t.Fatal(err)
+	}
+	if calls := fake.Calls("updateGroupMembers"); calls != 0 {
+		t.Errorf("granting an existing membership sent %d mutations", calls)
+	}
+
+	g := &v2.Grant{Entitlement: entitlement, Principal: userPrincipal(invitedID)}
+	if _, err := groups.Grant(ctx, g.Principal, entitlement); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := groups.Revoke(ctx, g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls := fake.Calls("updateGroupMembers"); calls != 2 {
+		t.Errorf("sent %d mutations, want one grant and one revoke", calls)
+	}
+	member, _, err := tg.client.IsGroupMember(ctx, platformID, invitedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member {
+		t.Error("user is still a member after the revoke")
+	}
+}
+
+func TestSyncedGroupMembershipIsRefused(t *testing.T) {
+	ctx := context.Background()
+	tg, fake := newTestConnector(t, Config{})
+	groups := groupBuilder(tg.client, tg.domain, false, false, nil, "Okta")
+	entitlement := groupMemberEntitlementOf(engID)
+
+	_, err := groups.Grant(ctx, userPrincipal(adaID), entitlement)
+	if err == nil || !strings.Contains(err.Error(), "change it in Okta") {
+		t.Errorf("granting membership of a SYNCED group: got error %v, want one pointing to Okta", err)
+	}
+	_, err = groups.Revoke(ctx, &v2.Grant{Entitlement: entitlement, Principal: userPrincipal(graceID)})
+	if err == nil || !strings.Contains(err.Error(), "change it in Okta") {
+		t.Errorf("revoking membership of a SYNCED group: got error %v, want one pointing to Okta", err)
+	}
+	if calls := fake.Calls("updateGroupMembers"); calls != 0 {
+		t.Errorf("sent %d mutations to a SYNCED group", calls)
+	}
+}
+
+func TestSyncedUserCanJoinManualGroup(t *testing.T) {
+	ctx := context.Background()
+	tg, _ := newTestConnector(t, Config{})
+	groups := groupBuilder(tg.client, tg.domain, false, false, nil, "")
+
+	if _, err := groups.Grant(ctx, userPrincipal(alanID), groupMemberEntitlementOf(platformID)); err != nil {
+		t.Fatal(err)
+	}
+	member, _, err := tg.client.IsGroupMember(ctx, platformID, alanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !member {
+		t.Error("SYNCED user is not a member of the MANUAL group after the grant")
+	}
+}
+
+func TestRevokeFromDeletedGroup(t *testing.T) {
+	ctx := context.Background()
+	tg, fake := newTestConnector(t, Config{})
+	groups := groupBuilder(tg.client, tg.domain, false, false, nil, "")
+	if _, err := tg.client.DeleteGroup(ctx, platformID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := groups.Revoke(ctx, &v2.Grant{Entitlement: groupMemberEntitlementOf(platformID), Principal: userPrincipal(adaID)}); err != nil {
+		t.Fatalf("revoking a membership of a deleted group failed: %v", err)
+	}
+	if calls := fake.Calls("updateGroupMembers"); calls != 0 {
+		t.Errorf("revoking a membership of a deleted group sent %d mutations", calls)
+	}
+}
+
+func TestRevokeKeepsRateLimitDescription(t *testing.T) {
+	ctx := context.Background()
+	tg, fake := newTestConnector(t, Config{})
+	groups := groupBuilder(tg.client, tg.domain, false, false, nil, "")
+	fake.InjectFault(twingatefake.Fault{Operation: "getUserGroups", StatusCode: http.StatusTooManyRequests, Message: "rate limited", RetryAfter: rateLimitWait})
+
+	annos, err := groups.Revoke(ctx, &v2.Grant{Entitlement: groupMemberEntitlementOf(platformID), Principal: userPrincipal(adaID)})
+	if !errors.Is(err, client.ErrRateLimited) {
+		t.Fatalf("got error %v, want ErrRateLimited", err)
+	}
+	rl := &v2.RateLimitDescription{}
+	ok, err := annos.Pick(rl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || rl.Status != v2.RateLimitDescription_STATUS_OVERLIMIT {
+		t.Errorf("revoke returned rate limit description %v, want OVERLIMIT", rl)
+	}
+}
+
+// syncedGroup returns the resource of a group as listed by a sync, with the member ETag it was synced with.
+func syncedGroup(t *testing.T, groups *groupResourceType, id string) *v2.Resource {
+	t.Helper()
+	resources, _, _, err := groups.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range resources {
+		if r.Id.Resource != id {
+			continue
+		}
+		etag := groups.membersETag(r)
+		if etag == nil {
+			t.Fatalf("group %s has no ETag", id)
+		}
+		etagAny, err := anypb.New(etag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Annotations = append(r.Annotations, etagAny)
+		return r
+	}
+	t.Fatalf("group %s was not listed", id)
+	return nil
+}
+
+func TestGrantsETagMatch(t *testing.T) {
+	ctx := context.Background()
+	tg, fake := newTestConnector(t, Config{})
+	groups := groupBuilder(tg.client, tg.domain, false, true, nil, "")
+	r := syncedGroup(t, groups, platformID)
+
+	// New users do not change the ETag of groups they are not members of.
+	if _, err := tg.client.CreateUser(ctx, "new@example.com", "", "", "MEMBER", false); err != nil {
+		t.Fatal(err)
+	}
+	grants, _, annos, err := groups.Grants(ctx, r, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grants) != 0 {
+		t.Errorf("got %d grants for an unchanged group, want none", len(grants))
+	}
+	if !annos.Contains(&v2.ETagMatch{}) {
+		t.Error("unchanged group has no ETag match")
+	}
+	if calls := fake.Calls("getGroupMembers"); calls != 0 {
+		t.Errorf("listed the members of an unchanged group %d times", calls)
+	}
+}
+
+func TestGrantsETagMismatch(t *testing.T) {
+	ctx := context.Background()
+	tg, _ := newTestConnector(t, Config{})
+	groups := groupBuilder(tg.client, tg.domain, false, true, nil, "")
+	r := syncedGroup(t, groups, platformID)
+
+	// The previous sync saw the group before its last update.
+	for _, a := range r.Annotations {
+		etag := &v2.ETag{}
+		if !a.MessageIs(etag) {
+			continue
+		}
+		if err := a.UnmarshalTo(etag); err != nil {
+			t.Fatal(err)
+		}
+		etag.Value = "2020-01-01T00:00:00Z"
+		if err := a.MarshalFrom(etag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	grants, _, annos, err := groups.Grants(ctx, r, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if annos.Contains(&v2.ETagMatch{}) {
+		t.Error("changed group has an ETag match")
+	}
+	if len(grants) != 2 {
+		t.Errorf("got %d grants for the changed group, want 2", len(grants))
+	}
+}
+
+func TestListGroupsRateLimited(t *testing.T) {
+	ctx := context.Background()
+	for _, prefetch := range []bool{false, true} {
+		tg, fake := newTestConnector(t, Config{})
+		groups := groupBuilder(tg.client, tg.domain, prefetch, false, nil, "")
+		fake.InjectFault(twingatefake.Fault{Operation: "getGroups", StatusCode: http.StatusTooManyRequests, Message: "rate limited", RetryAfter: rateLimitWait})
+		fake.InjectFault(twingatefake.Fault{Operation: "getGroupsWithMembers", StatusCode: http.StatusTooManyRequests, Message: "rate limited", RetryAfter: rateLimitWait})
+
+		_, _, annos, err := groups.List(ctx, nil, &pagination.Token{})
+		if !errors.Is(err, client.ErrRateLimited) {
+			t.Errorf("prefetch %v: got error %v, want ErrRateLimited", prefetch, err)
+		}
+		if !annos.Contains(&v2.RateLimitDescription{}) {
+			t.Errorf("prefetch %v: rate limited page has no rate limit description", prefetch)
+		}
+	}
+}
+
+func TestGroupGrantsRateLimited(t *testing.T) {
+	ctx := context.Background()
+	tg, fake := newTestConnector(t, Config{})
+	groups := groupBuilder(tg.client, tg.domain, false, false, nil, "")
+	fake.InjectFault(twingatefake.Fault{Operation: "getGroupMembers", StatusCode: http.StatusTooManyRequests, Message: "rate limited", RetryAfter: rateLimitWait})
+
+	_, next, _, err := groups.Grants(ctx, &v2.Resource{Id: groupID(platformID)}, &pagination.Token{})
+	if !errors.Is(err, client.ErrRateLimited) {
+		t.Fatalf("got error %v, want ErrRateLimited", err)
+	}
+	if next != "" {
+		t.Errorf("rate limited page returned next page %q", next)
+	}
+}
+
+func TestGrantsETagChangesWhenAMemberIsDeleted(t *testing.T) {
+	ctx := context.Background()
+	tg, _ := newTestConnector(t, Config{})
+	groups := groupBuilder(tg.client, tg.domain, false, true, nil, "")
+	previous := syncedGroup(t, groups, platformID)
+
+	// Twingate keeps the updatedAt of the group when a member is deleted.
+	if _, err := tg.client.DeleteUser(ctx, graceID); err != nil {
+		t.Fatal(err)
+	}
+	r := syncedGroup(t, groups, platformID)
+	r.Annotations[len(r.Annotations)-1] = previous.Annotations[len(previous.Annotations)-1]
+
+	grants, _, annos, err := groups.Grants(ctx, r, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if annos.Contains(&v2.ETagMatch{}) {
+		t.Error("group that lost a member has an ETag match")
+	}
+	if len(grants) != 1 || grants[0].Principal.Id.Resource != adaID {
+		t.Errorf("got %d grants, want the one of the remaining member", len(grants))
+	}
+}
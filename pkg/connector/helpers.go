@@ -1,12 +1,22 @@
 package connector
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-twingate/pkg/connector/client"
 )
 
+// ResourcesPageSize is the page size of lists that are not fetched from Twingate. Twingate lists use the adaptive page
+// sizes of the client.
 const ResourcesPageSize = 100
 
+// NestedMembersPageSize is the number of members fetched with each group when group members are prefetched.
+const NestedMembersPageSize = 50
+
 func annotationsForUserResourceType() annotations.Annotations {
 	annos := annotations.Annotations{}
 	annos.Update(&v2.SkipEntitlementsAndGrants{})
@@ -21,3 +31,53 @@ func Convert[T any, R any](slice []T, f func(in T) R) []R {
 	}
 	return ret
 }
+
+// joinList joins items as an English list, such as "users, groups, and roles".
+func joinList(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " and " + items[1]
+	default:
+		return strings.Join(items[:len(items)-1], ", ") + ", and " + items[len(items)-1]
+	}
+}
+
+// rateLimitAnnotations returns the annotations of a page that failed because Twingate still rejected it because of
+// the rate limit once the client stopped retrying, or nil for other errors. They carry the rate limit description, so
+// the page is retried once the limit resets.
+func rateLimitAnnotations(err error) annotations.Annotations {
+	rateLimitDescription := client.RateLimitDescription(err)
+	if rateLimitDescription == nil {
+		return nil
+	}
+	annos := annotations.Annotations{}
+	annos.WithRateLimiting(rateLimitDescription)
+	return annos
+}
+
+// cursorToken adds the page size of the next page to a Twingate cursor, so that a resumed sync requests pages of the
+// size it left off with. The last page has no cursor, and gets no token.
+func cursorToken(cursor string, pageSize uint32) string {
+	if cursor == "" {
+		return ""
+	}
+	return fmt.Sprintf("%d:%s", pageSize, cursor)
+}
+
+// parseCursorToken splits a token made by cursorToken into the cursor and the page size. Tokens saved by earlier
+// versions hold only the cursor and have no page size. Twingate cursors are base64, so they never contain a colon.
+func parseCursorToken(token string) (string, uint32) {
+	prefix, cursor, ok := strings.Cut(token, ":")
+	if !ok {
+		return token, 0
+	}
+	pageSize, err := strconv.ParseUint(prefix, 10, 32)
+	if err != nil {
+		return token, 0
+	}
+	return cursor, uint32(pageSize)
+}
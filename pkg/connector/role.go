@@ -21,6 +21,7 @@ type roleResourceType struct {
 	resourceType *v2.ResourceType
 	domain       string
 	client       *client.ConnectorClient
+	index        *roleIndex
 }
 
 func (o *roleResourceType) ResourceType(_ context.Context) *v2.ResourceType {
@@ -51,6 +52,9 @@ func roleResource(ctx context.Context, role *client.Role) (*v2.Resource, error)
 }
 
 func (o *roleResourceType) List(ctx context.Context, _ *v2.ResourceId, pt *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	// Listing roles starts a new sync, so role membership has to be fetched again.
+	o.index.reset()
+
 	roles, err := o.client.ListRoles(ctx)
 	if err != nil {
 		return nil, "", nil, err
@@ -98,30 +102,34 @@ func (o *roleResourceType) Grants(ctx context.Context, resource *v2.Resource, pt
 		})
 	}
 
-	resp, err := o.client.ListRoleGrants(ctx, resource.Id.Resource, bag.PageToken(), ResourcesPageSize)
+	// The page token holds the cursor of the users list, so a resumed sync continues with the users that follow
+	// the last page it served.
+	cursor, pageSize := parseCursorToken(bag.PageToken())
+	page, err := o.index.page(ctx, cursor, o.client.PageSize(client.ConnectionUsers, pageSize))
 	if err != nil {
-		return nil, "", nil, err
+		return nil, "", rateLimitAnnotations(err), err
 	}
 
-	var rv []*v2.Grant
-	for _, roleGrant := range resp.Grants {
+	members := page.members[resource.Id.Resource]
+	rv := make([]*v2.Grant, 0, len(members))
+	for _, userID := range members {
 		rv = append(rv, grant.NewGrant(
 			resource,
 			roleMemberEntitlement,
 			&v2.ResourceId{
 				ResourceType: resourceTypeUser.Id,
-				Resource:     roleGrant.PrincipalID,
+				Resource:     userID,
 			},
 		))
 	}
 
-	nextPage, err := bag.NextToken(resp.Pagination)
+	nextPage, err := bag.NextToken(cursorToken(page.next, o.client.PageSize(client.ConnectionUsers, 0)))
 	if err != nil {
 		return nil, "", nil, err
 	}
 	annotations := annotations.Annotations{}
-	if resp.RateLimitDescription != nil {
-		annotations.WithRateLimiting(resp.RateLimitDescription)
+	if page.rateLimitDescription != nil {
+		annotations.WithRateLimiting(page.rateLimitDescription)
 	}
 	return rv, nextPage, annotations, nil
 }
@@ -131,5 +139,6 @@ func roleBuilder(client *client.ConnectorClient, domain string) *roleResourceTyp
 		resourceType: resourceTypeRole,
 		client:       client,
 		domain:       domain,
+		index:        newRoleIndex(client),
 	}
 }
@@ -0,0 +1,81 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-twingate/pkg/connector/client"
+)
+
+// roleIndex holds the IDs of the users in each role, one page of the users list at a time. Every role walks the same
+// pages, so role grants cost one user listing per sync instead of one per role. Pages are keyed by the cursor of the
+// users list that fetched them, which stays valid when a sync is resumed by another process. Only user IDs are kept,
+// which keeps the index to a few megabytes even for tenants with 100k users.
+type roleIndex struct {
+	client *client.ConnectorClient
+
+	mu    sync.Mutex
+	pages map[string]*roleIndexPage
+}
+
+// roleIndexPage holds the role members found in one page of users.
+type roleIndexPage struct {
+	members map[string][]string
+	// next is the cursor of the next page of users, or empty on the last page.
+	next                 string
+	rateLimitDescription *v2.RateLimitDescription
+}
+
+func newRoleIndex(client *client.ConnectorClient) *roleIndex {
+	return &roleIndex{client: client, pages: make(map[string]*roleIndexPage)}
+}
+
+// userRoleID returns the ID of the role resource a user belongs to.
+func userRoleID(user *client.User) string {
+	if user.IsAdmin {
+		return "admin"
+	}
+	return "member"
+}
+
+// reset drops the index so that the next lookup lists the users again.
+func (r *roleIndex) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pages = make(map[string]*roleIndexPage)
+}
+
+// page returns the role members in the page of users that follows cursor, listing that page the first time it is
+// asked for after a reset. Only the listing of the one page is done while holding the lock. Pages served from the
+// index have no rate limit description.
+func (r *roleIndex) page(ctx context.Context, cursor string, pageSize uint32) (*roleIndexPage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.pages[cursor]; ok {
+		// The rate limit description was current when the page was listed, and says nothing about the rate limit
+		// now that no request is sent.
+		return &roleIndexPage{members: p.members, next: p.next}, nil
+	}
+	resp, err := r.client.ListUsers(ctx, cursor, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("twingate: error listing users for role grants: %w", err)
+	}
+
+	p := &roleIndexPage{
+		members:              make(map[string][]string),
+		next:                 resp.Pagination,
+		rateLimitDescription: resp.RateLimitDescription,
+	}
+	for _, user := range resp.Nodes {
+		if user == nil || user.ID == "" {
+			continue
+		}
+		roleID := userRoleID(user)
+		p.members[roleID] = append(p.members[roleID], user.ID)
+	}
+	r.pages[cursor] = p
+	return p, nil
+}
@@ -0,0 +1,101 @@
+package connector
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-twingate/pkg/connector/client"
+)
+
+func roleResourceOf(t *testing.T, id string) *v2.Resource {
+	t.Helper()
+	r, err := roleResource(context.Background(), &client.Role{Id: id, Name: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// roleGrants pages through the grants of a role from token, and returns the user IDs granted.
+func roleGrants(t *testing.T, roles *roleResourceType, roleID string, token string) []string {
+	t.Helper()
+	var rv []string
+	for {
+		grants, next, _, err := roles.Grants(context.Background(), roleResourceOf(t, roleID), &pagination.Token{Token: token})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, g := range grants {
+			rv = append(rv, g.Principal.Id.Resource)
+		}
+		if next == "" {
+			sort.Strings(rv)
+			return rv
+		}
+		token = next
+	}
+}
+
+func TestRoleGrantsShareOneUserListing(t *testing.T) {
+	tg, fake := newTestConnector(t, Config{PageSizes: map[client.ConnectionType]uint32{client.ConnectionUsers: 1}})
+	roles := roleBuilder(tg.client, tg.domain)
+
+	admins := roleGrants(t, roles, "admin", "")
+	members := roleGrants(t, roles, "member", "")
+	if len(admins) != 1 || admins[0] != adaID {
+		t.Errorf("admin role grants are %v, want %s", admins, adaID)
+	}
+	if len(members) != 3 {
+		t.Errorf("member role has %d grants, want 3", len(members))
+	}
+	// Four pages of one user, fetched once for both roles.
+	if calls := fake.Calls("getUsers"); calls != 4 {
+		t.Errorf("listed %d pages of users, want 4", calls)
+	}
+}
+
+func TestRoleGrantsResumeFromUserCursor(t *testing.T) {
+	ctx := context.Background()
+	config := Config{PageSizes: map[client.ConnectionType]uint32{client.ConnectionUsers: 2}}
+	tg, _ := newTestConnector(t, config)
+	roles := roleBuilder(tg.client, tg.domain)
+
+	grants, next, _, err := roles.Grants(ctx, roleResourceOf(t, "member"), &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grants) != 1 || grants[0].Principal.Id.Resource != graceID || next == "" {
+		t.Fatalf("first page has %d grants and token %q, want Grace and a token", len(grants), next)
+	}
+
+	// Another process resuming from the token continues after the users of the first page.
+	resumed := roleBuilder(tg.client, tg.domain)
+	rest := roleGrants(t, resumed, "member", next)
+	if len(rest) != 2 || rest[0] != invitedID || rest[1] != alanID {
+		t.Errorf("resumed role grants are %v, want %s and %s", rest, invitedID, alanID)
+	}
+}
+
+func TestRoleGrantsFromTheIndexHaveNoRateLimit(t *testing.T) {
+	ctx := context.Background()
+	tg, _ := newTestConnector(t, Config{})
+	roles := roleBuilder(tg.client, tg.domain)
+
+	_, _, annos, err := roles.Grants(ctx, roleResourceOf(t, "admin"), &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !annos.Contains(&v2.RateLimitDescription{}) {
+		t.Error("grants of the role that listed the users have no rate limit description")
+	}
+	_, _, annos, err = roles.Grants(ctx, roleResourceOf(t, "member"), &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if annos.Contains(&v2.RateLimitDescription{}) {
+		t.Error("grants served from the index have the rate limit description of an earlier request")
+	}
+}
@@ -0,0 +1,401 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-twingate/pkg/connector/client"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+var resourceTypeTenant = &v2.ResourceType{
+	Id:          "tenant",
+	DisplayName: "Tenant",
+}
+
+// TenantConfig is one Twingate network synced in multi-tenant mode.
+type TenantConfig struct {
+	// Name identifies the tenant in resource IDs. It defaults to the network name of Domain.
+	Name       string
+	Domain     string
+	ApiKey     string
+	APIKeyFile string
+	APIURL     string
+}
+
+var tenantNameRe = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// tenantName returns the name of a tenant, derived from its domain when it has none.
+func (t TenantConfig) tenantName() (string, error) {
+	name := t.Name
+	if name == "" && t.Domain != "" {
+		domain, err := client.NormalizeDomain(t.Domain)
+		if err != nil {
+			return "", err
+		}
+		name, _, _ = strings.Cut(domain, ".")
+	}
+	if name == "" {
+		return "", fmt.Errorf("twingate: tenants need a name or a domain")
+	}
+	if !tenantNameRe.MatchString(name) {
+		return "", fmt.Errorf("twingate: invalid tenant name %q, use letters, digits, '.', '_' and '-'", name)
+	}
+	return name, nil
+}
+
+// ValidateTenants returns an error when a tenant has no usable name or two tenants have the same name.
+func ValidateTenants(tenants []TenantConfig) error {
+	seen := make(map[string]bool, len(tenants))
+	for _, t := range tenants {
+		name, err := t.tenantName()
+		if err != nil {
+			return err
+		}
+		if seen[name] {
+			return fmt.Errorf("twingate: tenant %q is configured twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// tenant is one Twingate network of a multi-tenant connector, synced by its own single-tenant connector.
+type tenant struct {
+	name string
+	*Twingate
+}
+
+// newTenants creates a single-tenant connector for every tenant of config. Recordings of each tenant go to a
+// subdirectory named after it, since different tenants send identical requests.
+func newTenants(ctx context.Context, config Config) ([]*tenant, error) {
+	if err := ValidateTenants(config.Tenants); err != nil {
+		return nil, err
+	}
+	rv := make([]*tenant, 0, len(config.Tenants))
+	// The tenants created before one fails are closed, since the caller never sees them.
+	fail := func(err error) ([]*tenant, error) {
+		for _, t := range rv {
+			_ = t.Close()
+		}
+		return nil, err
+	}
+	for _, t := range config.Tenants {
+		name, err := t.tenantName()
+		if err != nil {
+			return fail(err)
+		}
+		tenantConfig := config
+		tenantConfig.Tenants = nil
+		tenantConfig.Domain = t.Domain
+		tenantConfig.ApiKey = t.ApiKey
+		tenantConfig.APIKeyFile = t.APIKeyFile
+		tenantConfig.APIURL = t.APIURL
+		if config.RecordDir != "" {
+			tenantConfig.RecordDir = filepath.Join(config.RecordDir, name)
+		}
+		if config.ReplayDir != "" {
+			tenantConfig.ReplayDir = filepath.Join(config.ReplayDir, name)
+		}
+		c, err := New(ctx, tenantConfig)
+		if err != nil {
+			return fail(fmt.Errorf("tenant %s: %w", name, err))
+		}
+		rv = append(rv, &tenant{name: name, Twingate: c})
+	}
+	return rv, nil
+}
+
+// tenantResourceSyncers returns the syncer of the tenant resource type followed by one syncer per selected resource
+// type that dispatches to the syncers of every tenant.
+func (c *Twingate) tenantResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
+	rv := []connectorbuilder.ResourceSyncer{&tenantResourceType{tenants: c.tenants}}
+	for _, rt := range resourceTypes {
+		if !c.resourceTypes[rt.Id] {
+			continue
+		}
+		scoped := &tenantScopedSyncer{resourceType: rt, syncers: make(map[string]connectorbuilder.ResourceSyncer)}
+		provisioner := true
+		for _, t := range c.tenants {
+			for _, s := range t.ResourceSyncers(ctx) {
+				if s.ResourceType(ctx).Id != rt.Id {
+					continue
+				}
+				scoped.syncers[t.name] = s
+				if _, ok := s.(connectorbuilder.ResourceProvisioner); !ok {
+					provisioner = false
+				}
+			}
+		}
+		if provisioner {
+			rv = append(rv, &tenantScopedProvisioner{scoped})
+		} else {
+			rv = append(rv, scoped)
+		}
+	}
+	return rv
+}
+
+// tenantResourceType lists one resource per tenant. Every other resource is a child of its tenant.
+type tenantResourceType struct {
+	tenants []*tenant
+}
+
+func (o *tenantResourceType) ResourceType(_ context.Context) *v2.ResourceType {
+	return resourceTypeTenant
+}
+
+func (o *tenantResourceType) List(ctx context.Context, _ *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	rv := make([]*v2.Resource, 0, len(o.tenants))
+	for _, t := range o.tenants {
+		annos := annotations.Annotations{}
+		if t.domain != "" {
+			annos.Update(&v2.ExternalLink{Url: "https://" + t.domain})
+		}
+		for _, rt := range resourceTypes {
+			if t.resourceTypes[rt.Id] {
+				annos.Append(&v2.ChildResourceType{ResourceTypeId: rt.Id})
+			}
+		}
+		profile, err := structpb.NewStruct(map[string]interface{}{"domain": t.domain})
+		if err != nil {
+			return nil, "", nil, err
+		}
+		annos.Update(profile)
+		rv = append(rv, &v2.Resource{
+			Id:          &v2.ResourceId{ResourceType: resourceTypeTenant.Id, Resource: t.name},
+			DisplayName: t.name,
+			Annotations: annos,
+		})
+	}
+	return rv, "", nil, nil
+}
+
+func (o *tenantResourceType) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (o *tenantResourceType) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// tenantScopedSyncer syncs one resource type of every tenant. Resources are listed as children of their tenant, and
+// the IDs of resources, entitlements and grants are prefixed with the tenant name and a slash, so that the IDs of
+// different tenants cannot collide. The syncers of the tenants only ever see their own IDs.
+type tenantScopedSyncer struct {
+	resourceType *v2.ResourceType
+	syncers      map[string]connectorbuilder.ResourceSyncer
+}
+
+func (o *tenantScopedSyncer) ResourceType(_ context.Context) *v2.ResourceType {
+	return o.resourceType
+}
+
+func (o *tenantScopedSyncer) List(ctx context.Context, parentResourceID *v2.ResourceId, pt *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	// Resources are only listed under their tenant.
+	if parentResourceID == nil || parentResourceID.ResourceType != resourceTypeTenant.Id {
+		return nil, "", nil, nil
+	}
+	name := parentResourceID.Resource
+	s, ok := o.syncers[name]
+	if !ok {
+		return nil, "", nil, fmt.Errorf("twingate: unknown tenant %q", name)
+	}
+	resources, next, annos, err := s.List(ctx, nil, pt)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("tenant %s: %w", name, err)
+	}
+	for i, r := range resources {
+		resources[i] = tenantResource(name, r)
+	}
+	return resources, next, tenantAnnotations(annos, func(id string) string { return tenantEntitlementID(name, id) }), nil
+}
+
+func (o *tenantScopedSyncer) Entitlements(ctx context.Context, resource *v2.Resource, pt *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	name, s, inner, err := o.route(resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	entitlements, next, annos, err := s.Entitlements(ctx, inner, pt)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("tenant %s: %w", name, err)
+	}
+	for i, e := range entitlements {
+		entitlements[i] = tenantEntitlement(name, e)
+	}
+	return entitlements, next, tenantAnnotations(annos, func(id string) string { return tenantEntitlementID(name, id) }), nil
+}
+
+func (o *tenantScopedSyncer) Grants(ctx context.Context, resource *v2.Resource, pt *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	name, s, inner, err := o.route(resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	grants, next, annos, err := s.Grants(ctx, inner, pt)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("tenant %s: %w", name, err)
+	}
+	for i, g := range grants {
+		grants[i] = tenantGrant(name, g)
+	}
+	return grants, next, tenantAnnotations(annos, func(id string) string { return tenantEntitlementID(name, id) }), nil
+}
+
+// route returns the tenant of a namespaced resource, its syncer, and the resource as the tenant knows it.
+func (o *tenantScopedSyncer) route(resource *v2.Resource) (string, connectorbuilder.ResourceSyncer, *v2.Resource, error) {
+	name, inner, err := tenantInnerResource(resource)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	s, ok := o.syncers[name]
+	if !ok {
+		return "", nil, nil, fmt.Errorf("twingate: unknown tenant %q", name)
+	}
+	return name, s, inner, nil
+}
+
+// tenantScopedProvisioner is a tenantScopedSyncer for a resource type whose syncers also provision.
+type tenantScopedProvisioner struct {
+	*tenantScopedSyncer
+}
+
+func (o *tenantScopedProvisioner) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	name, s, resource, err := o.route(entitlement.Resource)
+	if err != nil {
+		return nil, err
+	}
+	principalTenant, innerPrincipal, err := tenantInnerResource(principal)
+	if err != nil {
+		return nil, err
+	}
+	if principalTenant != name {
+		return nil, fmt.Errorf("twingate: cannot grant an entitlement of tenant %s to a principal of tenant %s", name, principalTenant)
+	}
+	innerEntitlement := proto.Clone(entitlement).(*v2.Entitlement)
+	innerEntitlement.Resource = resource
+	innerEntitlement.Id = innerEntitlementID(entitlement.Id)
+	annos, err := s.(connectorbuilder.ResourceProvisioner).Grant(ctx, innerPrincipal, innerEntitlement)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", name, err)
+	}
+	return annos, nil
+}
+
+func (o *tenantScopedProvisioner) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	name, s, resource, err := o.route(grant.Entitlement.Resource)
+	if err != nil {
+		return nil, err
+	}
+	principalTenant, innerPrincipal, err := tenantInnerResource(grant.Principal)
+	if err != nil {
+		return nil, err
+	}
+	if principalTenant != name {
+		return nil, fmt.Errorf("twingate: cannot revoke an entitlement of tenant %s from a principal of tenant %s", name, principalTenant)
+	}
+	innerGrant := proto.Clone(grant).(*v2.Grant)
+	innerGrant.Principal = innerPrincipal
+	innerGrant.Entitlement.Resource = resource
+	innerGrant.Entitlement.Id = innerEntitlementID(grant.Entitlement.Id)
+	innerGrant.Id = fmt.Sprintf("%s:%s:%s", innerGrant.Entitlement.Id, innerPrincipal.Id.ResourceType, innerPrincipal.Id.Resource)
+	annos, err := s.(connectorbuilder.ResourceProvisioner).Revoke(ctx, innerGrant)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", name, err)
+	}
+	return annos, nil
+}
+
+// tenantID prefixes the ID of a resource of a tenant with the tenant name.
+func tenantID(name string, id *v2.ResourceId) *v2.ResourceId {
+	return &v2.ResourceId{ResourceType: id.ResourceType, Resource: name + "/" + id.Resource}
+}
+
+// tenantResource returns a copy of a resource of a tenant with a prefixed ID, as a child of the tenant.
+func tenantResource(name string, r *v2.Resource) *v2.Resource {
+	rv := proto.Clone(r).(*v2.Resource)
+	rv.Id = tenantID(name, r.Id)
+	rv.ParentResourceId = &v2.ResourceId{ResourceType: resourceTypeTenant.Id, Resource: name}
+	return rv
+}
+
+// tenantInnerResource returns the tenant of a prefixed resource and a copy of the resource as the tenant knows it.
+// The annotations are copied too, since incremental sync reads the ETag of the previous sync from them.
+func tenantInnerResource(r *v2.Resource) (string, *v2.Resource, error) {
+	name, id, ok := strings.Cut(r.GetId().GetResource(), "/")
+	if !ok {
+		return "", nil, fmt.Errorf("twingate: resource %s has no tenant", r.GetId().GetResource())
+	}
+	rv := proto.Clone(r).(*v2.Resource)
+	rv.Id = &v2.ResourceId{ResourceType: r.Id.ResourceType, Resource: id}
+	rv.ParentResourceId = nil
+	rv.Annotations = tenantAnnotations(rv.Annotations, innerEntitlementID)
+	return name, rv, nil
+}
+
+// tenantEntitlementID prefixes the resource part of an entitlement or grant ID, which starts with the resource
+// type and the resource ID separated by colons.
+func tenantEntitlementID(name string, id string) string {
+	resourceType, rest, ok := strings.Cut(id, ":")
+	if !ok {
+		return id
+	}
+	return resourceType + ":" + name + "/" + rest
+}
+
+// innerEntitlementID removes the tenant prefix added by tenantEntitlementID.
+func innerEntitlementID(id string) string {
+	resourceType, rest, ok := strings.Cut(id, ":")
+	if !ok {
+		return id
+	}
+	_, rest, ok = strings.Cut(rest, "/")
+	if !ok {
+		return id
+	}
+	return resourceType + ":" + rest
+}
+
+// tenantEntitlement returns a copy of an entitlement of a tenant with prefixed IDs.
+func tenantEntitlement(name string, e *v2.Entitlement) *v2.Entitlement {
+	rv := proto.Clone(e).(*v2.Entitlement)
+	rv.Id = tenantEntitlementID(name, e.Id)
+	if e.Resource != nil {
+		rv.Resource = tenantResource(name, e.Resource)
+	}
+	return rv
+}
+
+// tenantGrant returns a copy of a grant of a tenant with prefixed IDs. Principals are always of the same tenant.
+func tenantGrant(name string, g *v2.Grant) *v2.Grant {
+	rv := proto.Clone(g).(*v2.Grant)
+	rv.Entitlement = tenantEntitlement(name, g.Entitlement)
+	rv.Principal = tenantResource(name, g.Principal)
+	rv.Id = fmt.Sprintf("%s:%s:%s", rv.Entitlement.Id, rv.Principal.Id.ResourceType, rv.Principal.Id.Resource)
+	return rv
+}
+
+// tenantAnnotations maps the entitlement IDs held by ETag annotations, and returns the other annotations unchanged.
+func tenantAnnotations(annos annotations.Annotations, mapID func(string) string) annotations.Annotations {
+	if len(annos) == 0 {
+		return annos
+	}
+	etag := &v2.ETag{}
+	if ok, err := annos.Pick(etag); err == nil && ok {
+		etag.EntitlementId = mapID(etag.EntitlementId)
+		annos.Update(etag)
+	}
+	etagMatch := &v2.ETagMatch{}
+	if ok, err := annos.Pick(etagMatch); err == nil && ok {
+		etagMatch.EntitlementId = mapID(etagMatch.EntitlementId)
+		annos.Update(etagMatch)
+	}
+	return annos
+}
@@ -0,0 +1,190 @@
+package connector
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// newTestTenants returns a multi-tenant connector configured by config with one fake Twingate serving the test dataset
+// per tenant, and the syncer of resource type rt.
+func newTestTenants(t *testing.T, config Config, rt *v2.ResourceType, names ...string) (*tenantScopedSyncer, map[string]*twingatefake.Server) {
+	t.Helper()
+	fakes := make(map[string]*twingatefake.Server, len(names))
+	for _, name := range names {
+		fake := twingatefake.New(testDataset(t), twingatefake.WithAPIKey(testAPIKey))
+		server := httptest.NewServer(fake)
+		t.Cleanup(server.Close)
+		fakes[name] = fake
+		config.Tenants = append(config.Tenants, TenantConfig{
+			Name:   name,
+			Domain: name,
+			ApiKey: testAPIKey,
+			APIURL: server.URL + "/api/graphql/",
+		})
+	}
+	tg, err := New(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range tg.ResourceSyncers(context.Background()) {
+		if s.ResourceType(context.Background()).Id != rt.Id {
+			continue
+		}
+		switch s := s.(type) {
+		case *tenantScopedSyncer:
+			return s, fakes
+		case *tenantScopedProvisioner:
+			return s.tenantScopedSyncer, fakes
+		}
+	}
+	t.Fatalf("no tenant syncer for %s", rt.Id)
+	return nil, nil
+}
+
+func tenantGroup(t *testing.T, groups *tenantScopedSyncer, name string, id string) *v2.Resource {
+	t.Helper()
+	resources, _, _, err := groups.List(context.Background(), &v2.ResourceId{ResourceType: resourceTypeTenant.Id, Resource: name}, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range resources {
+		if r.Id.Resource == name+"/"+id {
+			return r
+		}
+	}
+	t.Fatalf("group %s of tenant %s was not listed", id, name)
+	return nil
+}
+
+func TestTenantEntitlementIDRoundTrip(t *testing.T) {
+	for id, want := range map[string]string{
+		"group:R3JvdXA6Mg==:member": "group:east/R3JvdXA6Mg==:member",
+		"role:admin:assigned":       "role:east/admin:assigned",
+		// Base64 IDs may contain slashes; only the first one separates the tenant.
+		"group:R3J/dXA=:member": "group:east/R3J/dXA=:member",
+		"no-resource-type":      "no-resource-type",
+	} {
+		got := tenantEntitlementID("east", id)
+		if got != want {
+			t.Errorf("tenantEntitlementID(east, %q) = %q, want %q", id, got, want)
+		}
+		if inner := innerEntitlementID(got); inner != id {
+			t.Errorf("innerEntitlementID(%q) = %q, want %q", got, inner, id)
+		}
+	}
+}
+
+func TestTenantAnnotationsMapETags(t *testing.T) {
+	annos := annotations.Annotations{}
+	annos.Update(&v2.ETag{Value: "2024-01-01T00:00:00Z", EntitlementId: "group:R3JvdXA6Mg==:member"})
+	annos.Update(&v2.ETagMatch{EntitlementId: "group:R3JvdXA6Mg==:member"})
+	annos.Update(&v2.RateLimitDescription{Limit: 60})
+
+	annos = tenantAnnotations(annos, func(id string) string { return tenantEntitlementID("east", id) })
+	etag := &v2.ETag{}
+	if _, err := annos.Pick(etag); err != nil {
+		t.Fatal(err)
+	}
+	if etag.EntitlementId != "group:east/R3JvdXA6Mg==:member" || etag.Value != "2024-01-01T00:00:00Z" {
+		t.Errorf("got ETag %v", etag)
+	}
+	etagMatch := &v2.ETagMatch{}
+	if _, err := annos.Pick(etagMatch); err != nil {
+		t.Fatal(err)
+	}
+	if etagMatch.EntitlementId != "group:east/R3JvdXA6Mg==:member" {
+		t.Errorf("got ETag match %v", etagMatch)
+	}
+	if !annos.Contains(&v2.RateLimitDescription{}) {
+		t.Error("other annotations were dropped")
+	}
+
+	annos = tenantAnnotations(annos, innerEntitlementID)
+	if _, err := annos.Pick(etag); err != nil {
+		t.Fatal(err)
+	}
+	if etag.EntitlementId != "group:R3JvdXA6Mg==:member" {
+		t.Errorf("got inner ETag %v", etag)
+	}
+}
+
+func TestTenantGrantsETagMatch(t *testing.T) {
+	ctx := context.Background()
+	groups, fakes := newTestTenants(t, Config{IncrementalSync: true}, resourceTypeGroup, "east", "west")
+	r := tenantGroup(t, groups, "east", platformID)
+
+	_, _, annos, err := groups.Grants(ctx, r, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag := &v2.ETag{}
+	if ok, err := annos.Pick(etag); err != nil || !ok {
+		t.Fatalf("grants have no ETag: %v", err)
+	}
+	if etag.EntitlementId != "group:east/"+platformID+":"+groupMemberEntitlement {
+		t.Errorf("ETag is for entitlement %q", etag.EntitlementId)
+	}
+
+	// The next sync passes the ETag back on the resource, with the tenant prefix.
+	etagAny, err := anypb.New(etag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Annotations = append(r.Annotations, etagAny)
+	grants, _, annos, err := groups.Grants(ctx, r, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	etagMatch := &v2.ETagMatch{}
+	if ok, err := annos.Pick(etagMatch); err != nil || !ok {
+		t.Fatalf("grants of an unchanged group have no ETag match: %v", err)
+	}
+	if len(grants) != 0 || etagMatch.EntitlementId != etag.EntitlementId {
+		t.Errorf("got %d grants and ETag match %v, want none and the ETag of the previous sync", len(grants), etagMatch)
+	}
+	if calls := fakes["east"].Calls("getGroupMembers"); calls != 1 {
+		t.Errorf("listed the members of the group %d times, want once", calls)
+	}
+}
+
+func TestTenantProvisioningStaysInTenant(t *testing.T) {
+	ctx := context.Background()
+	groups, fakes := newTestTenants(t, Config{}, resourceTypeGroup, "east", "west")
+	provisioner := connectorbuilder.ResourceProvisioner(&tenantScopedProvisioner{groups})
+	entitlement := &v2.Entitlement{
+		Id:       tenantEntitlementID("east", "group:"+platformID+":"+groupMemberEntitlement),
+		Resource: &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeGroup.Id, Resource: "east/" + platformID}},
+	}
+	westUser := &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: "west/" + invitedID}}
+	eastUser := &v2.Resource{Id: &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: "east/" + adaID}}
+
+	if _, err := provisioner.Grant(ctx, westUser, entitlement); err == nil {
+		t.Error("granted an entitlement of one tenant to a user of another")
+	}
+	if _, err := provisioner.Revoke(ctx, &v2.Grant{Entitlement: entitlement, Principal: westUser}); err == nil {
+		t.Error("revoked an entitlement of one tenant from a user of another")
+	}
+	for name, fake := range fakes {
+		if calls := fake.Calls("updateGroupMembers"); calls != 0 {
+			t.Errorf("tenant %s received %d membership changes across tenants", name, calls)
+		}
+	}
+
+	if _, err := provisioner.Revoke(ctx, &v2.Grant{Entitlement: entitlement, Principal: eastUser}); err != nil {
+		t.Fatal(err)
+	}
+	if calls := fakes["east"].Calls("updateGroupMembers"); calls != 1 {
+		t.Errorf("revoking within the tenant sent %d membership changes, want 1", calls)
+	}
+	if calls := fakes["west"].Calls("updateGroupMembers"); calls != 0 {
+		t.Errorf("revoking in tenant east sent %d membership changes to tenant west", calls)
+	}
+}
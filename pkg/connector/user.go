@@ -2,7 +2,7 @@ package connector
 
 import (
 	"context"
-	"fmt"
+	"strings"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
@@ -17,29 +17,70 @@ type userResourceType struct {
 	resourceType *v2.ResourceType
 	domain       string
 	client       *client.ConnectorClient
+	// identityProvider names the identity provider that SYNCED users come from, if configured.
+	identityProvider string
 }
 
 func (o *userResourceType) ResourceType(_ context.Context) *v2.ResourceType {
 	return o.resourceType
 }
 
-func userResource(ctx context.Context, user *client.User) (*v2.Resource, error) {
+func userStatus(state string) v2.UserTrait_Status_Status {
+	if state == "DISABLED" {
+		return v2.UserTrait_Status_STATUS_DISABLED
+	}
+	return v2.UserTrait_Status_STATUS_ENABLED
+}
+
+// userDisplayName returns the name of a user, or the email for users without a name, such as invited users.
+func userDisplayName(user *client.User) string {
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	if user.Email != "" {
+		return user.Email
+	}
+	return user.ID
+}
+
+// isSyncedUser reports whether a user is provisioned from an identity provider, which overwrites changes made in
+// Twingate.
+func isSyncedUser(user *client.User) bool {
+	return user.Type == string(client.UserTypeSynced)
+}
+
+func userResource(ctx context.Context, user *client.User, identityProvider string) (*v2.Resource, error) {
 	profile := map[string]interface{}{
 		"first_name": user.FirstName,
 		"last_name":  user.LastName,
 		"is_admin":   user.IsAdmin,
 		"email":      user.Email,
 		"id":         user.ID,
+		"role":       user.Role,
+		"state":      user.State,
+		"user_type":  user.Type,
+		// The user trait of baton-sdk v0.1.7 has no created or last login fields, so the timestamps are kept in the
+		// profile like those of groups.
+		"created_at":                    user.CreatedAt,
+		"updated_at":                    user.UpdatedAt,
+		"synced_from_identity_provider": isSyncedUser(user),
+	}
+	if isSyncedUser(user) && identityProvider != "" {
+		profile["identity_provider"] = identityProvider
 	}
 
 	userTraitOptions := []resource.UserTraitOption{
 		resource.WithUserProfile(profile),
 		resource.WithEmail(user.Email, true),
-		resource.WithStatus(v2.UserTrait_Status_STATUS_ENABLED),
+		resource.WithStatus(userStatus(user.State)),
+		resource.WithAccountType(v2.UserTrait_ACCOUNT_TYPE_HUMAN),
+	}
+	if user.Email != "" {
+		userTraitOptions = append(userTraitOptions, resource.WithUserLogin(user.Email))
 	}
 
 	resource, err := resource.NewUserResource(
-		fmt.Sprintf("%s %s", user.FirstName, user.LastName),
+		userDisplayName(user),
 		resourceTypeUser,
 		user.ID,
 		userTraitOptions,
@@ -65,20 +106,21 @@ func (o *userResourceType) List(ctx context.Context, _ *v2.ResourceId, pt *pagin
 		})
 	}
 
-	resp, err := o.client.ListUsers(ctx, bag.PageToken(), ResourcesPageSize)
+	cursor, pageSize := parseCursorToken(bag.PageToken())
+	resp, err := o.client.ListUsers(ctx, cursor, o.client.PageSize(client.ConnectionUsers, pageSize))
 	if err != nil {
-		return nil, "", nil, err
+		return nil, "", rateLimitAnnotations(err), err
 	}
 
-	rv := make([]*v2.Resource, 0, len(resp.Users))
-	for _, user := range resp.Users {
+	rv := make([]*v2.Resource, 0, len(resp.Nodes))
+	for _, user := range resp.Nodes {
 		if user.ID == "" {
 			l.Error("twingate: user had no id", zap.String("email", user.Email))
 			continue
 		}
 
 		userCopy := user
-		ur, err := userResource(ctx, userCopy)
+		ur, err := userResource(ctx, userCopy, o.identityProvider)
 		if err != nil {
 			return nil, "", nil, err
 		}
@@ -86,7 +128,7 @@ func (o *userResourceType) List(ctx context.Context, _ *v2.ResourceId, pt *pagin
 		rv = append(rv, ur)
 	}
 
-	nextPage, err := bag.NextToken(resp.Pagination)
+	nextPage, err := bag.NextToken(cursorToken(resp.Pagination, o.client.PageSize(client.ConnectionUsers, 0)))
 	if err != nil {
 		return nil, "", nil, err
 	}
@@ -107,10 +149,19 @@ func (o *userResourceType) Grants(_ context.Context, _ *v2.Resource, _ *paginati
 	return nil, "", nil, nil
 }
 
-func userBuilder(client *client.ConnectorClient, domain string) *userResourceType {
+// identityProviderName returns the configured name of the identity provider for error messages, or a generic one.
+func identityProviderName(identityProvider string) string {
+	if identityProvider == "" {
+		return "the identity provider"
+	}
+	return identityProvider
+}
+
+func userBuilder(client *client.ConnectorClient, domain string, identityProvider string) *userResourceType {
 	return &userResourceType{
-		resourceType: resourceTypeUser,
-		domain:       domain,
-		client:       client,
+		resourceType:     resourceTypeUser,
+		domain:           domain,
+		client:           client,
+		identityProvider: identityProvider,
 	}
 }
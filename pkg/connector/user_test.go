@@ -0,0 +1,35 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-twingate/pkg/connector/client"
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+)
+
+func userID(id string) *v2.ResourceId {
+	return &v2.ResourceId{ResourceType: resourceTypeUser.Id, Resource: id}
+}
+
+func TestListUsersRateLimited(t *testing.T) {
+	ctx := context.Background()
+	tg, fake := newTestConnector(t, Config{})
+	users := userBuilder(tg.client, tg.domain, "")
+	fake.InjectFault(twingatefake.Fault{Operation: "getUsers", StatusCode: http.StatusTooManyRequests, Message: "rate limited", RetryAfter: rateLimitWait})
+
+	resources, next, annos, err := users.List(ctx, nil, &pagination.Token{})
+	if !errors.Is(err, client.ErrRateLimited) {
+		t.Fatalf("got error %v, want ErrRateLimited", err)
+	}
+	if len(resources) != 0 || next != "" {
+		t.Errorf("rate limited page returned %d users and next page %q", len(resources), next)
+	}
+	if !annos.Contains(&v2.RateLimitDescription{}) {
+		t.Error("rate limited page has no rate limit description")
+	}
+}
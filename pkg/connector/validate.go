@@ -0,0 +1,140 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-twingate/pkg/connector/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// syncPermissions returns the permissions needed to sync the selected resource types. Role grants are built from the
+// users list, so roles need read_users too.
+func (c *Twingate) syncPermissions() []client.Permission {
+	var rv []client.Permission
+	if c.resourceTypes[resourceTypeUser.Id] || c.resourceTypes[resourceTypeRole.Id] {
+		rv = append(rv, client.PermissionReadUsers)
+	}
+	if c.resourceTypes[resourceTypeGroup.Id] {
+		rv = append(rv, client.PermissionReadGroups)
+	}
+	return rv
+}
+
+// provisioningPermissions returns the permissions needed to provision the selected resource types, none when
+// provisioning is disabled.
+func (c *Twingate) provisioningPermissions() []client.Permission {
+	if !c.provisioning {
+		return nil
+	}
+	var rv []client.Permission
+	if c.resourceTypes[resourceTypeGroup.Id] {
+		rv = append(rv, client.PermissionWriteGroups)
+	}
+	return rv
+}
+
+// Validate checks what the API key is allowed to do and fails when it lacks a permission the connector needs. Only
+// the permissions of the selected resource types are checked, and write permissions only with provisioning enabled.
+// Checking never changes the tenant, so write permissions are reported as unverified. The full report is logged and
+// returned as an annotation.
+func (c *Twingate) Validate(ctx context.Context) (annotations.Annotations, error) {
+	if len(c.tenants) > 0 {
+		return c.validateTenants(ctx)
+	}
+	syncPermissions := c.syncPermissions()
+	report, err := c.client.ProbePermissions(ctx, append(syncPermissions, c.provisioningPermissions()...))
+	if err != nil {
+		return nil, err
+	}
+	l := ctxzap.Extract(ctx)
+	l.Info("twingate: api key permissions",
+		zap.Strings("granted", permissionNames(report.Granted)),
+		zap.Strings("missing", permissionNames(report.Missing)),
+		zap.Strings("unverified", permissionNames(report.Unverified)),
+	)
+	if len(report.Unverified) > 0 {
+		l.Warn("twingate: provisioning is enabled, but write permissions cannot be checked without changing the tenant; a key without them fails on the first change",
+			zap.Strings("permissions", permissionNames(report.Unverified)),
+		)
+	}
+
+	if missing := missingPermissions(report, syncPermissions); len(missing) > 0 {
+		return nil, fmt.Errorf("twingate: the api key is missing permissions needed to sync: %s", strings.Join(missing, ", "))
+	}
+
+	annos := annotations.Annotations{}
+	reportAnnotation, err := permissionReportAnnotation(report)
+	if err != nil {
+		return nil, err
+	}
+	annos.Update(reportAnnotation)
+	return annos, nil
+}
+
+func missingPermissions(report *client.PermissionReport, needed []client.Permission) []string {
+	var rv []string
+	for _, p := range needed {
+		if !report.Has(p) {
+			rv = append(rv, string(p))
+		}
+	}
+	return rv
+}
+
+func permissionNames(permissions []client.Permission) []string {
+	rv := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		rv = append(rv, string(p))
+	}
+	return rv
+}
+
+// permissionReportAnnotation returns the report as a struct with granted, missing and unverified lists of permission
+// names.
+func permissionReportAnnotation(report *client.PermissionReport) (*structpb.Struct, error) {
+	granted := make([]interface{}, 0, len(report.Granted))
+	for _, p := range permissionNames(report.Granted) {
+		granted = append(granted, p)
+	}
+	missing := make([]interface{}, 0, len(report.Missing))
+	for _, p := range permissionNames(report.Missing) {
+		missing = append(missing, p)
+	}
+	unverified := make([]interface{}, 0, len(report.Unverified))
+	for _, p := range permissionNames(report.Unverified) {
+		unverified = append(unverified, p)
+	}
+	return structpb.NewStruct(map[string]interface{}{
+		"granted":    granted,
+		"missing":    missing,
+		"unverified": unverified,
+	})
+}
+
+// validateTenants validates every tenant, and returns their reports by tenant name as one annotation.
+func (c *Twingate) validateTenants(ctx context.Context) (annotations.Annotations, error) {
+	reports := make(map[string]interface{}, len(c.tenants))
+	for _, t := range c.tenants {
+		annos, err := t.Validate(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", t.name, err)
+		}
+		report := &structpb.Struct{}
+		if _, err := annos.Pick(report); err != nil {
+			return nil, err
+		}
+		reports[t.name] = report.AsMap()
+	}
+	reportAnnotation, err := structpb.NewStruct(map[string]interface{}{"tenants": reports})
+	if err != nil {
+		return nil, err
+	}
+	annos := annotations.Annotations{}
+	annos.Update(reportAnnotation)
+	return annos, nil
+}
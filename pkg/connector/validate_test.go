@@ -0,0 +1,86 @@
+package connector
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// validateReport runs Validate and returns its permission report.
+func validateReport(t *testing.T, tg *Twingate) map[string]interface{} {
+	t.Helper()
+	annos, err := tg.Validate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := &structpb.Struct{}
+	if _, err := annos.Pick(report); err != nil {
+		t.Fatal(err)
+	}
+	return report.AsMap()
+}
+
+// mutations returns the number of mutations the fake received.
+func mutations(fake *twingatefake.Server) int {
+	n := 0
+	for _, op := range []string{"updateGroupMembers", "renameGroup", "createGroup", "deleteGroup", "createUser", "updateUserState", "deleteUser"} {
+		n += fake.Calls(op)
+	}
+	return n
+}
+
+func TestValidateIsReadOnly(t *testing.T) {
+	tg, fake := newTestConnector(t, Config{Provisioning: true}, twingatefake.WithReadOnly())
+	report := validateReport(t, tg)
+
+	want := map[string]interface{}{
+		"granted":    []interface{}{"read_users", "read_groups"},
+		"missing":    []interface{}{},
+		"unverified": []interface{}{"write_groups"},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got report %v, want %v", report, want)
+	}
+	if n := mutations(fake); n != 0 {
+		t.Errorf("Validate sent %d mutations", n)
+	}
+}
+
+func TestValidateWithoutProvisioningChecksOnlyReads(t *testing.T) {
+	tg, _ := newTestConnector(t, Config{})
+	report := validateReport(t, tg)
+	if unverified := report["unverified"].([]interface{}); len(unverified) != 0 {
+		t.Errorf("write permissions %v are reported without provisioning", unverified)
+	}
+}
+
+func TestValidateFollowsResourceTypes(t *testing.T) {
+	tg, fake := newTestConnector(t, Config{ResourceTypes: []string{resourceTypeUser.Id}, Provisioning: true})
+	report := validateReport(t, tg)
+
+	want := map[string]interface{}{
+		"granted":    []interface{}{"read_users"},
+		"missing":    []interface{}{},
+		"unverified": []interface{}{},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got report %v, want %v", report, want)
+	}
+	if calls := fake.Calls("getGroupsWithMembers"); calls != 0 {
+		t.Errorf("Validate listed groups %d times without syncing them", calls)
+	}
+}
+
+func TestValidateFailsWithoutReadAccess(t *testing.T) {
+	tg, fake := newTestConnector(t, Config{})
+	fake.InjectFault(twingatefake.Fault{Operation: "getGroupsWithMembers", Message: "Permission denied to read groups"})
+
+	_, err := tg.Validate(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "read_groups") {
+		t.Errorf("got error %v, want one naming read_groups", err)
+	}
+}
@@ -0,0 +1,95 @@
+package golden
+
+import (
+	"context"
+	"io"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/types"
+	"google.golang.org/grpc"
+)
+
+// serverClient calls a ConnectorServer in-process through the ConnectorClient interface the syncer expects.
+type serverClient struct {
+	server types.ConnectorServer
+}
+
+var _ types.ConnectorClient = (*serverClient)(nil)
+
+func (c *serverClient) ListResourceTypes(ctx context.Context, in *v2.ResourceTypesServiceListResourceTypesRequest, _ ...grpc.CallOption) (*v2.ResourceTypesServiceListResourceTypesResponse, error) {
+	return c.server.ListResourceTypes(ctx, in)
+}
+
+func (c *serverClient) ListResources(ctx context.Context, in *v2.ResourcesServiceListResourcesRequest, _ ...grpc.CallOption) (*v2.ResourcesServiceListResourcesResponse, error) {
+	return c.server.ListResources(ctx, in)
+}
+
+func (c *serverClient) ListEntitlements(ctx context.Context, in *v2.EntitlementsServiceListEntitlementsRequest, _ ...grpc.CallOption) (*v2.EntitlementsServiceListEntitlementsResponse, error) {
+	return c.server.ListEntitlements(ctx, in)
+}
+
+func (c *serverClient) ListGrants(ctx context.Context, in *v2.GrantsServiceListGrantsRequest, _ ...grpc.CallOption) (*v2.GrantsServiceListGrantsResponse, error) {
+	return c.server.ListGrants(ctx, in)
+}
+
+func (c *serverClient) GetMetadata(ctx context.Context, in *v2.ConnectorServiceGetMetadataRequest, _ ...grpc.CallOption) (*v2.ConnectorServiceGetMetadataResponse, error) {
+	return c.server.GetMetadata(ctx, in)
+}
+
+func (c *serverClient) Validate(ctx context.Context, in *v2.ConnectorServiceValidateRequest, _ ...grpc.CallOption) (*v2.ConnectorServiceValidateResponse, error) {
+	return c.server.Validate(ctx, in)
+}
+
+func (c *serverClient) Grant(ctx context.Context, in *v2.GrantManagerServiceGrantRequest, _ ...grpc.CallOption) (*v2.GrantManagerServiceGrantResponse, error) {
+	return c.server.Grant(ctx, in)
+}
+
+func (c *serverClient) Revoke(ctx context.Context, in *v2.GrantManagerServiceRevokeRequest, _ ...grpc.CallOption) (*v2.GrantManagerServiceRevokeResponse, error) {
+	return c.server.Revoke(ctx, in)
+}
+
+// GetAsset runs the server side of the stream to completion and replays the sent messages to the caller.
+func (c *serverClient) GetAsset(ctx context.Context, in *v2.AssetServiceGetAssetRequest, _ ...grpc.CallOption) (v2.AssetService_GetAssetClient, error) {
+	server := &assetServerStream{ctx: ctx}
+	if err := c.server.GetAsset(in, server); err != nil {
+		return nil, err
+	}
+	return &assetClientStream{ctx: ctx, msgs: server.msgs}, nil
+}
+
+// assetServerStream collects the messages sent by GetAsset. Only the methods used by the connector builder are
+// implemented.
+type assetServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	msgs []*v2.AssetServiceGetAssetResponse
+}
+
+func (s *assetServerStream) Send(msg *v2.AssetServiceGetAssetResponse) error {
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+func (s *assetServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// assetClientStream replays collected GetAsset messages. Only the methods used by the syncer are implemented.
+type assetClientStream struct {
+	grpc.ClientStream
+	ctx  context.Context
+	msgs []*v2.AssetServiceGetAssetResponse
+}
+
+func (s *assetClientStream) Recv() (*v2.AssetServiceGetAssetResponse, error) {
+	if len(s.msgs) == 0 {
+		return nil, io.EOF
+	}
+	msg := s.msgs[0]
+	s.msgs = s.msgs[1:]
+	return msg, nil
+}
+
+func (s *assetClientStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,227 @@
+// Package golden runs a full sync of the connector into a c1z file and compares the synced resources,
+// entitlements and grants with a checked-in golden file.
+package golden
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/dotc1z"
+	sdkSync "github.com/conductorone/baton-sdk/pkg/sync"
+	"github.com/conductorone/baton-twingate/pkg/connector"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Snapshot is the content of a sync, in a stable order so that it can be compared between runs.
+type Snapshot struct {
+	Resources    []json.RawMessage `json:"resources"`
+	Entitlements []json.RawMessage `json:"entitlements"`
+	Grants       []json.RawMessage `json:"grants"`
+}
+
+// Sync runs a full sync of a connector built from cfg into the c1z file at c1zPath.
+func Sync(ctx context.Context, cfg connector.Config, c1zPath string) error {
+	tg, err := connector.New(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	server, err := connectorbuilder.NewConnector(ctx, tg)
+	if err != nil {
+		return err
+	}
+
+	syncer, err := sdkSync.NewSyncer(ctx, &serverClient{server: server}, sdkSync.WithC1ZPath(c1zPath))
+	if err != nil {
+		return err
+	}
+	err = syncer.Sync(ctx)
+	if err != nil {
+		_ = syncer.Close(ctx)
+		return err
+	}
+	return syncer.Close(ctx)
+}
+
+// Load reads the latest finished sync of a c1z file.
+func Load(ctx context.Context, c1zPath string) (*Snapshot, error) {
+	f, err := dotc1z.NewC1ZFile(ctx, c1zPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	syncID, err := f.LatestFinishedSync(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if syncID == "" {
+		return nil, errors.New("golden: c1z file has no finished sync")
+	}
+	if err := f.ViewSync(ctx, syncID); err != nil {
+		return nil, err
+	}
+
+	rv := &Snapshot{}
+	pageToken := ""
+	for {
+		resp, err := f.ListResources(ctx, &v2.ResourcesServiceListResourcesRequest{PageToken: pageToken})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resp.List {
+			if err := rv.add(&rv.Resources, r); err != nil {
+				return nil, err
+			}
+		}
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+	for {
+		resp, err := f.ListEntitlements(ctx, &v2.EntitlementsServiceListEntitlementsRequest{PageToken: pageToken})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range resp.List {
+			if err := rv.add(&rv.Entitlements, e); err != nil {
+				return nil, err
+			}
+		}
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+	for {
+		resp, err := f.ListGrants(ctx, &v2.GrantsServiceListGrantsRequest{PageToken: pageToken})
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range resp.List {
+			if err := rv.add(&rv.Grants, g); err != nil {
+				return nil, err
+			}
+		}
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+
+	for _, list := range [][]json.RawMessage{rv.Resources, rv.Entitlements, rv.Grants} {
+		sort.Slice(list, func(i, j int) bool {
+			return bytes.Compare(list[i], list[j]) < 0
+		})
+	}
+	return rv, nil
+}
+
+// volatileAnnotations are left out of snapshots because they depend on previous syncs rather than on the tenant.
+var volatileAnnotations = map[string]bool{
+	"type.googleapis.com/c1.connector.v2.ETag": true,
+}
+
+// add appends msg as JSON. protojson output is not stable between runs, so it is re-encoded with encoding/json,
+// which sorts object keys.
+func (s *Snapshot) add(list *[]json.RawMessage, msg proto.Message) error {
+	b, err := protojson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	v := map[string]interface{}{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if annos, ok := v["annotations"].([]interface{}); ok {
+		kept := make([]interface{}, 0, len(annos))
+		for _, a := range annos {
+			if anno, ok := a.(map[string]interface{}); ok && volatileAnnotations[fmt.Sprint(anno["@type"])] {
+				continue
+			}
+			kept = append(kept, a)
+		}
+		if len(kept) == 0 {
+			delete(v, "annotations")
+		} else {
+			v["annotations"] = kept
+		}
+	}
+	b, err = json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	*list = append(*list, b)
+	return nil
+}
+
+// Marshal returns the snapshot as indented JSON.
+func (s *Snapshot) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// Compare checks the snapshot against the golden file at path. With update set, the golden file is rewritten
+// instead.
+func Compare(s *Snapshot, path string, update bool) error {
+	got, err := s.Marshal()
+	if err != nil {
+		return err
+	}
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, got, 0o600)
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("golden: %w (run go test with -update to create it)", err)
+	}
+	if bytes.Equal(got, want) {
+		return nil
+	}
+	return fmt.Errorf("golden: sync output differs from %s (run go test with -update to accept it):\n%s", path, diff(string(want), string(got)))
+}
+
+// diff returns the lines that only appear in want, prefixed with -, and in got, prefixed with +.
+func diff(want string, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	wantCount := make(map[string]int)
+	for _, l := range wantLines {
+		wantCount[l]++
+	}
+	gotCount := make(map[string]int)
+	for _, l := range gotLines {
+		gotCount[l]++
+	}
+
+	sb := &strings.Builder{}
+	for _, l := range wantLines {
+		if gotCount[l] > 0 {
+			gotCount[l]--
+			continue
+		}
+		sb.WriteString("- " + l + "\n")
+	}
+	for _, l := range gotLines {
+		if wantCount[l] > 0 {
+			wantCount[l]--
+			continue
+		}
+		sb.WriteString("+ " + l + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package golden
+
+import (
+	"context"
+	"flag"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/conductorone/baton-twingate/pkg/connector"
+	"github.com/conductorone/baton-twingate/pkg/twingatefake"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files with the output of TestSync and TestSyncTenants.")
+
+const (
+	fakeAPIKey = "golden-api-key"
+	dataPath   = "../twingatefake/testdata/tenant.json"
+	// tenantGolden is the output of a sync of the sample tenant, whatever the connector options.
+	tenantGolden = "testdata/tenant.golden.json"
+	// tenantsGolden is the output of a multi-tenant sync of two copies of the sample tenant.
+	tenantsGolden = "testdata/tenants.golden.json"
+)
+
+// newFake serves a fresh copy of the sample tenant, so that tests cannot affect each other.
+func newFake(t *testing.T) (*twingatefake.Server, *httptest.Server) {
+	t.Helper()
+	data, err := twingatefake.LoadDataset(dataPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := twingatefake.New(data, twingatefake.WithAPIKey(fakeAPIKey))
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func fakeConfig(server *httptest.Server) connector.Config {
+	return connector.Config{
+		Domain: "golden",
+		ApiKey: fakeAPIKey,
+		APIURL: server.URL + "/api/graphql/",
+	}
+}
+
+// checkSync runs syncs syncs of cfg into the same c1z file and compares the last one with the golden file at path.
+func checkSync(t *testing.T, cfg connector.Config, syncs int, path string, update bool) {
+	t.Helper()
+	ctx := context.Background()
+	c1zPath := filepath.Join(t.TempDir(), "sync.c1z")
+	for i := 0; i < syncs; i++ {
+		if err := Sync(ctx, cfg, c1zPath); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshot, err := Load(ctx, c1zPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Compare(snapshot, path, update); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSync(t *testing.T) {
+	_, server := newFake(t)
+	checkSync(t, fakeConfig(server), 1, tenantGolden, *update)
+}
+
+func TestSyncPrefetchGroupMembers(t *testing.T) {
+	_, server := newFake(t)
+	cfg := fakeConfig(server)
+	cfg.PrefetchGroupMembers = true
+	checkSync(t, cfg, 1, tenantGolden, false)
+}
+
+func TestSyncIncremental(t *testing.T) {
+	_, server := newFake(t)
+	cfg := fakeConfig(server)
+	cfg.IncrementalSync = true
+	// The second sync reuses the memberships of the first one.
+	checkSync(t, cfg, 2, tenantGolden, false)
+}
+
+// tenantsConfig returns the configuration of a multi-tenant sync of two fresh copies of the sample tenant.
+func tenantsConfig(t *testing.T) connector.Config {
+	cfg := connector.Config{}
+	for _, name := range []string{"east", "west"} {
+		_, server := newFake(t)
+		cfg.Tenants = append(cfg.Tenants, connector.TenantConfig{
+			Name:   name,
+			Domain: name,
+			ApiKey: fakeAPIKey,
+			APIURL: server.URL + "/api/graphql/",
+		})
+	}
+	return cfg
+}
+
+func TestSyncTenants(t *testing.T) {
+	checkSync(t, tenantsConfig(t), 1, tenantsGolden, *update)
+}
@@ -0,0 +1,69 @@
+package twingatefake
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type User struct {
+	ID        string `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+	IsAdmin   bool   `json:"isAdmin"`
+	// Role defaults to ADMIN for admins and MEMBER for the others.
+	Role  string `json:"role,omitempty"`
+	State string `json:"state"`
+	// Type defaults to MANUAL.
+	Type string `json:"type,omitempty"`
+}
+
+type Group struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	IsActive  bool     `json:"isActive"`
+	Type      string   `json:"type"`
+	CreatedAt string   `json:"createdAt"`
+	UpdatedAt string   `json:"updatedAt"`
+	UserIDs   []string `json:"userIds"`
+}
+
+// Dataset is the tenant served by the fake server.
+type Dataset struct {
+	Users  []*User  `json:"users"`
+	Groups []*Group `json:"groups"`
+}
+
+// LoadDataset reads a dataset from a JSON file.
+func LoadDataset(path string) (*Dataset, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data := &Dataset{}
+	if err := json.Unmarshal(b, data); err != nil {
+		return nil, fmt.Errorf("twingatefake: invalid dataset %s: %w", path, err)
+	}
+	return data, nil
+}
+
+func (d *Dataset) user(id string) *User {
+	for _, u := range d.Users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (d *Dataset) group(id string) *Group {
+	for _, g := range d.Groups {
+		if g.ID == id {
+			return g
+		}
+	}
+	return nil
+}
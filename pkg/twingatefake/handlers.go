@@ -0,0 +1,326 @@
+package twingatefake
+
+import (
+	"fmt"
+	"time"
+)
+
+type handler func(s *Server, v vars) (interface{}, error)
+
+// handlers maps the operation names used by the connector client to their implementation.
+var handlers = map[string]handler{
+	"getUsers":             getUsers,
+	"getGroups":            getGroups,
+	"getGroupsWithMembers": getGroupsWithMembers,
+	"getGroupMembers":      getGroupMembers,
+	"getGroupDetails":      getGroupDetails,
+	"getUserDetails":       getUserDetails,
+	"getUserGroups":        getUserGroups,
+	"updateGroupMembers":   updateGroupMembers,
+	"renameGroup":          renameGroup,
+	"createGroup":          createGroup,
+	"deleteGroup":          deleteGroup,
+	"createUser":           createUser,
+	"updateUserState":      updateUserState,
+	"deleteUser":           deleteUser,
+}
+
+func userNode(u *User) interface{} {
+	role := u.Role
+	if role == "" {
+		role = "MEMBER"
+		if u.IsAdmin {
+			role = "ADMIN"
+		}
+	}
+	userType := u.Type
+	if userType == "" {
+		userType = "MANUAL"
+	}
+	return map[string]interface{}{
+		"id":        u.ID,
+		"firstName": u.FirstName,
+		"lastName":  u.LastName,
+		"email":     u.Email,
+		"createdAt": u.CreatedAt,
+		"updatedAt": u.UpdatedAt,
+		"isAdmin":   u.IsAdmin,
+		"role":      role,
+		"state":     u.State,
+		"type":      userType,
+	}
+}
+
+func groupNode(g *Group) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        g.ID,
+		"name":      g.Name,
+		"isActive":  g.IsActive,
+		"type":      g.Type,
+		"createdAt": g.CreatedAt,
+		"updatedAt": g.UpdatedAt,
+	}
+}
+
+// members returns the users of a group, skipping member IDs that do not match a user.
+func (s *Server) members(g *Group) []*User {
+	users := make([]*User, 0, len(g.UserIDs))
+	for _, id := range g.UserIDs {
+		if u := s.data.user(id); u != nil {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+func mutationResult(err error, entity interface{}) map[string]interface{} {
+	if err != nil {
+		return map[string]interface{}{"ok": false, "error": err.Error(), "entity": nil}
+	}
+	return map[string]interface{}{"ok": true, "error": nil, "entity": entity}
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+func getUsers(s *Server, v vars) (interface{}, error) {
+	users, err := connection(s.data.Users, v.string("after"), v.int("first", 0), userNode)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"users": users}, nil
+}
+
+func getGroups(s *Server, v vars) (interface{}, error) {
+	groups, err := connection(s.data.Groups, v.string("after"), v.int("first", 0), func(g *Group) interface{} {
+		node := groupNode(g)
+		node["users"] = map[string]interface{}{"totalCount": len(s.members(g))}
+		return node
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"groups": groups}, nil
+}
+
+func getGroupsWithMembers(s *Server, v vars) (interface{}, error) {
+	var nestedErr error
+	groups, err := connection(s.data.Groups, v.string("after"), v.int("first", 0), func(g *Group) interface{} {
+		node := groupNode(g)
+		users, err := connection(s.members(g), "", v.int("membersFirst", 0), userNode)
+		if err != nil {
+			nestedErr = err
+		}
+		node["users"] = users
+		return node
+	})
+	if err != nil {
+		return nil, err
+	}
+	if nestedErr != nil {
+		return nil, nestedErr
+	}
+	return map[string]interface{}{"groups": groups}, nil
+}
+
+func getGroupMembers(s *Server, v vars) (interface{}, error) {
+	g := s.data.group(v.string("groupID"))
+	if g == nil {
+		return map[string]interface{}{"group": nil}, nil
+	}
+	users, err := connection(s.members(g), v.string("after"), v.int("first", 0), userNode)
+	if err != nil {
+		return nil, err
+	}
+	node := groupNode(g)
+	node["users"] = users
+	return map[string]interface{}{"group": node}, nil
+}
+
+func getGroupDetails(s *Server, v vars) (interface{}, error) {
+	g := s.data.group(v.string("groupID"))
+	if g == nil {
+		return map[string]interface{}{"group": nil}, nil
+	}
+	return map[string]interface{}{"group": groupNode(g)}, nil
+}
+
+func getUserDetails(s *Server, v vars) (interface{}, error) {
+	u := s.data.user(v.string("userID"))
+	if u == nil {
+		return map[string]interface{}{"user": nil}, nil
+	}
+	return map[string]interface{}{"user": userNode(u)}, nil
+}
+
+func getUserGroups(s *Server, v vars) (interface{}, error) {
+	u := s.data.user(v.string("userID"))
+	if u == nil {
+		return map[string]interface{}{"user": nil}, nil
+	}
+	var groups []*Group
+	for _, g := range s.data.Groups {
+		for _, id := range g.UserIDs {
+			if id == u.ID {
+				groups = append(groups, g)
+				break
+			}
+		}
+	}
+	page, err := connection(groups, v.string("after"), v.int("first", 0), func(g *Group) interface{} {
+		return groupNode(g)
+	})
+	if err != nil {
+		return nil, err
+	}
+	node := map[string]interface{}{"id": u.ID, "groups": page}
+	return map[string]interface{}{"user": node}, nil
+}
+
+func updateGroupMembers(s *Server, v vars) (interface{}, error) {
+	g := s.data.group(v.string("id"))
+	if g == nil {
+		return map[string]interface{}{"groupUpdate": mutationResult(fmt.Errorf("Group with id %s not found", v.string("id")), nil)}, nil
+	}
+	added := v.strings("addedUserIds")
+	for _, id := range added {
+		if s.data.user(id) == nil {
+			return map[string]interface{}{"groupUpdate": mutationResult(fmt.Errorf("User with id %s does not exist", id), nil)}, nil
+		}
+	}
+
+	removed := make(map[string]bool)
+	for _, id := range v.strings("removedUserIds") {
+		removed[id] = true
+	}
+	userIDs := make([]string, 0, len(g.UserIDs)+len(added))
+	present := make(map[string]bool)
+	for _, id := range g.UserIDs {
+		if !removed[id] {
+			userIDs = append(userIDs, id)
+			present[id] = true
+		}
+	}
+	for _, id := range added {
+		if !present[id] {
+			userIDs = append(userIDs, id)
+			present[id] = true
+		}
+	}
+	g.UserIDs = userIDs
+	g.UpdatedAt = now()
+	return map[string]interface{}{"groupUpdate": mutationResult(nil, groupNode(g))}, nil
+}
+
+func renameGroup(s *Server, v vars) (interface{}, error) {
+	g := s.data.group(v.string("id"))
+	if g == nil {
+		return map[string]interface{}{"groupUpdate": mutationResult(fmt.Errorf("Group with id %s not found", v.string("id")), nil)}, nil
+	}
+	g.Name = v.string("name")
+	g.UpdatedAt = now()
+	return map[string]interface{}{"groupUpdate": mutationResult(nil, groupNode(g))}, nil
+}
+
+func createGroup(s *Server, v vars) (interface{}, error) {
+	name := v.string("name")
+	if name == "" {
+		return map[string]interface{}{"groupCreate": mutationResult(fmt.Errorf("name is required"), nil)}, nil
+	}
+	userIDs := v.strings("userIds")
+	for _, id := range userIDs {
+		if s.data.user(id) == nil {
+			return map[string]interface{}{"groupCreate": mutationResult(fmt.Errorf("User with id %s does not exist", id), nil)}, nil
+		}
+	}
+	g := &Group{
+		ID:        s.newID("Group"),
+		Name:      name,
+		IsActive:  true,
+		Type:      "MANUAL",
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		UserIDs:   userIDs,
+	}
+	s.data.Groups = append(s.data.Groups, g)
+	return map[string]interface{}{"groupCreate": mutationResult(nil, groupNode(g))}, nil
+}
+
+func deleteGroup(s *Server, v vars) (interface{}, error) {
+	id := v.string("id")
+	for i, g := range s.data.Groups {
+		if g.ID != id {
+			continue
+		}
+		if g.Type != "MANUAL" {
+			return map[string]interface{}{"groupDelete": mutationResult(fmt.Errorf("%s groups cannot be deleted", g.Type), nil)}, nil
+		}
+		s.data.Groups = append(s.data.Groups[:i], s.data.Groups[i+1:]...)
+		return map[string]interface{}{"groupDelete": mutationResult(nil, nil)}, nil
+	}
+	return map[string]interface{}{"groupDelete": mutationResult(fmt.Errorf("Group with id %s not found", id), nil)}, nil
+}
+
+func createUser(s *Server, v vars) (interface{}, error) {
+	email := v.string("email")
+	if email == "" {
+		return map[string]interface{}{"userCreate": mutationResult(fmt.Errorf("email is required"), nil)}, nil
+	}
+	for _, u := range s.data.Users {
+		if u.Email == email {
+			return map[string]interface{}{"userCreate": mutationResult(fmt.Errorf("User with email %s already exists", email), nil)}, nil
+		}
+	}
+	state := "PENDING"
+	if !v.bool("shouldSendInvite") {
+		state = "ACTIVE"
+	}
+	u := &User{
+		ID:        s.newID("User"),
+		FirstName: v.string("firstName"),
+		LastName:  v.string("lastName"),
+		Email:     email,
+		CreatedAt: now(),
+		UpdatedAt: now(),
+		IsAdmin:   v.string("role") == "ADMIN",
+		Role:      v.string("role"),
+		State:     state,
+	}
+	s.data.Users = append(s.data.Users, u)
+	return map[string]interface{}{"userCreate": mutationResult(nil, userNode(u))}, nil
+}
+
+func updateUserState(s *Server, v vars) (interface{}, error) {
+	u := s.data.user(v.string("id"))
+	if u == nil {
+		return map[string]interface{}{"userDetailsUpdate": mutationResult(fmt.Errorf("User with id %s not found", v.string("id")), nil)}, nil
+	}
+	state := v.string("state")
+	if state != "ACTIVE" && state != "DISABLED" {
+		return nil, fmt.Errorf("invalid user state %q", state)
+	}
+	u.State = state
+	u.UpdatedAt = now()
+	return map[string]interface{}{"userDetailsUpdate": mutationResult(nil, userNode(u))}, nil
+}
+
+func deleteUser(s *Server, v vars) (interface{}, error) {
+	id := v.string("id")
+	for i, u := range s.data.Users {
+		if u.ID != id {
+			continue
+		}
+		s.data.Users = append(s.data.Users[:i], s.data.Users[i+1:]...)
+		for _, g := range s.data.Groups {
+			for j, memberID := range g.UserIDs {
+				if memberID == id {
+					g.UserIDs = append(g.UserIDs[:j], g.UserIDs[j+1:]...)
+					break
+				}
+			}
+		}
+		return map[string]interface{}{"userDelete": mutationResult(nil, nil)}, nil
+	}
+	return map[string]interface{}{"userDelete": mutationResult(fmt.Errorf("User with id %s not found", id), nil)}, nil
+}
@@ -0,0 +1,108 @@
+package twingatefake
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Proxy is an http.Handler acting as a forward proxy that requires basic authentication, like an egress proxy. It
+// tunnels CONNECT requests for https and forwards plain http requests.
+type Proxy struct {
+	username string
+	password string
+
+	mu       sync.Mutex
+	requests int
+}
+
+// NewProxy returns a proxy that only accepts clients authenticating with username and password.
+func NewProxy(username string, password string) *Proxy {
+	return &Proxy{username: username, password: password}
+}
+
+// Requests returns the number of authenticated requests the proxy has served, counting a tunnel as one request.
+func (p *Proxy) Requests() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.requests
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The Proxy-Authorization header has the syntax of the Authorization header that BasicAuth reads.
+	auth := &http.Request{Header: http.Header{"Authorization": r.Header.Values("Proxy-Authorization")}}
+	if username, password, ok := auth.BasicAuth(); !ok || username != p.username || password != p.password {
+		w.Header().Set("Proxy-Authenticate", `Basic realm="twingate-fake"`)
+		http.Error(w, "proxy authentication required", http.StatusProxyAuthRequired)
+		return
+	}
+	p.mu.Lock()
+	p.requests++
+	p.mu.Unlock()
+
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	p.forward(w, r)
+}
+
+// tunnel connects the client to the requested host and copies bytes both ways until either side closes.
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	upstream, err := net.DialTimeout("tcp", r.Host, 10*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "tunnels are not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		conn.Close()
+		upstream.Close()
+		return
+	}
+	go func() {
+		defer upstream.Close()
+		_, _ = io.Copy(upstream, conn)
+	}()
+	go func() {
+		defer conn.Close()
+		_, _ = io.Copy(conn, upstream)
+	}()
+}
+
+// forward sends a plain http request to its destination and copies the response back.
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) {
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, r.URL.String(), r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Header = r.Header.Clone()
+	req.Header.Del("Proxy-Authorization")
+	req.Header.Del("Proxy-Connection")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for name, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(name, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
@@ -0,0 +1,314 @@
+// Package twingatefake implements an in-memory stand-in for the Twingate GraphQL API. It understands the
+// operations sent by the connector client, identified by their operation name, and serves them from a Dataset.
+package twingatefake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPageSize is used when a paginated query does not ask for a page size.
+const DefaultPageSize = 50
+
+var operationNamePattern = regexp.MustCompile(`^\s*(?:query|mutation)\s+(\w+)`)
+
+// Fault makes matching requests fail.
+type Fault struct {
+	// Operation restricts the fault to one GraphQL operation name. Empty matches every operation.
+	Operation string
+	// StatusCode is the HTTP status returned. When zero the request fails with a GraphQL error instead.
+	StatusCode int
+	// Message is the GraphQL error message, or the response body of HTTP faults.
+	Message string
+	// RetryAfter is sent as the Retry-After header of HTTP faults, in whole seconds, when set.
+	RetryAfter time.Duration
+	// Count is the number of requests that fail. Zero fails every matching request.
+	Count int
+	// Drop closes the connection without a response, like a connection reset by a proxy. StatusCode and Message are
+	// ignored.
+	Drop bool
+}
+
+type Option func(s *Server)
+
+// WithAPIKey makes the server reject requests that do not send apiKey in the X-API-KEY header.
+func WithAPIKey(apiKey string) Option {
+	return func(s *Server) {
+		s.apiKey = apiKey
+	}
+}
+
+// WithReadOnly makes the server answer every mutation with a permission error, like a read-only API key.
+func WithReadOnly() Option {
+	return func(s *Server) {
+		s.readOnly = true
+	}
+}
+
+// Server is an http.Handler serving the Twingate GraphQL API from memory.
+type Server struct {
+	mu       sync.Mutex
+	data     *Dataset
+	apiKey   string
+	readOnly bool
+	faults   []*Fault
+	calls    map[string]int
+	nextID   int
+}
+
+func New(data *Dataset, opts ...Option) *Server {
+	if data == nil {
+		data = &Dataset{}
+	}
+	s := &Server{
+		data:  data,
+		calls: make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// InjectFault adds a fault. Faults are checked in the order they were added.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Calls returns the number of requests received for an operation name.
+func (s *Server) Calls(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[operation]
+}
+
+type request struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type gqlError struct {
+	Message string `json:"message"`
+}
+
+type response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []gqlError  `json:"errors,omitempty"`
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.apiKey != "" && r.Header.Get("X-API-KEY") != s.apiKey {
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &request{}
+	if err := json.Unmarshal(body, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	match := operationNamePattern.FindStringSubmatch(req.Query)
+	if match == nil {
+		writeJSON(w, response{Errors: []gqlError{{Message: "twingatefake: only named operations are supported"}}})
+		return
+	}
+	operation := match[1]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[operation]++
+
+	if f := s.fault(operation); f != nil {
+		if f.Drop {
+			dropConnection(w)
+			return
+		}
+		if f.StatusCode != 0 {
+			if f.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter/time.Second)))
+			}
+			http.Error(w, f.Message, f.StatusCode)
+			return
+		}
+		writeJSON(w, response{Errors: []gqlError{{Message: f.Message}}})
+		return
+	}
+
+	if s.readOnly && strings.HasPrefix(strings.TrimSpace(req.Query), "mutation") {
+		writeJSON(w, response{Errors: []gqlError{{Message: "Permission denied: the API key is read-only"}}})
+		return
+	}
+
+	handler, ok := handlers[operation]
+	if !ok {
+		writeJSON(w, response{Errors: []gqlError{{Message: fmt.Sprintf("twingatefake: unknown operation %s", operation)}}})
+		return
+	}
+	data, err := handler(s, vars(req.Variables))
+	if err != nil {
+		writeJSON(w, response{Errors: []gqlError{{Message: err.Error()}}})
+		return
+	}
+	writeJSON(w, response{Data: data})
+}
+
+// fault returns the first fault matching operation and uses it up.
+func (s *Server) fault(operation string) *Fault {
+	for i, f := range s.faults {
+		if f.Operation != "" && f.Operation != operation {
+			continue
+		}
+		if f.Count > 0 {
+			f.Count--
+			if f.Count == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// dropConnection closes the connection of w without writing a response.
+func dropConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		// HTTP/2 connections cannot be hijacked, so the stream is aborted instead.
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	_ = conn.Close()
+}
+
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:fake-%d", prefix, s.nextID)))
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+type vars map[string]interface{}
+
+func (v vars) string(key string) string {
+	s, _ := v[key].(string)
+	return s
+}
+
+func (v vars) int(key string, def int) int {
+	switch n := v[key].(type) {
+	case float64:
+		return int(n)
+	case string:
+		i, err := strconv.Atoi(n)
+		if err == nil {
+			return i
+		}
+	}
+	return def
+}
+
+func (v vars) bool(key string) bool {
+	b, _ := v[key].(bool)
+	return b
+}
+
+func (v vars) strings(key string) []string {
+	list, _ := v[key].([]interface{})
+	rv := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			rv = append(rv, s)
+		}
+	}
+	return rv
+}
+
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte("cursor:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(b), "cursor:"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return offset, nil
+}
+
+// connection builds a GraphQL connection of the page of nodes selected by after and first.
+func connection[T any](nodes []T, after string, first int, node func(T) interface{}) (map[string]interface{}, error) {
+	start := 0
+	if after != "" {
+		offset, err := decodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		start = offset + 1
+	}
+	if start > len(nodes) {
+		start = len(nodes)
+	}
+	if first <= 0 {
+		first = DefaultPageSize
+	}
+	end := start + first
+	if end > len(nodes) {
+		end = len(nodes)
+	}
+
+	edges := make([]interface{}, 0, end-start)
+	for i := start; i < end; i++ {
+		edges = append(edges, map[string]interface{}{
+			"cursor": encodeCursor(i),
+			"node":   node(nodes[i]),
+		})
+	}
+	endCursor := ""
+	if end > start {
+		endCursor = encodeCursor(end - 1)
+	}
+	return map[string]interface{}{
+		"edges":      edges,
+		"totalCount": len(nodes),
+		"pageInfo": map[string]interface{}{
+			"endCursor":   endCursor,
+			"hasNextPage": end < len(nodes),
+		},
+	}, nil
+}
@@ -0,0 +1,318 @@
+// Command introspect downloads the schema of the Twingate Admin API with an introspection query and writes it as
+// GraphQL SDL, the format genqlient reads. It is run by make update-schema.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } }
+}`
+
+const header = `# Schema of the Twingate Admin API, generated by make update-schema. Do not edit it by hand. genqlient checks the
+# operations in operations.graphql against it when generating generated.go.
+
+`
+
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+type inputValue struct {
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Type         typeRef `json:"type"`
+	DefaultValue *string `json:"defaultValue"`
+}
+
+type fullType struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Fields      []struct {
+		Name              string       `json:"name"`
+		Description       string       `json:"description"`
+		Args              []inputValue `json:"args"`
+		Type              typeRef      `json:"type"`
+		IsDeprecated      bool         `json:"isDeprecated"`
+		DeprecationReason string       `json:"deprecationReason"`
+	} `json:"fields"`
+	InputFields []inputValue `json:"inputFields"`
+	Interfaces  []typeRef    `json:"interfaces"`
+	EnumValues  []struct {
+		Name              string `json:"name"`
+		Description       string `json:"description"`
+		IsDeprecated      bool   `json:"isDeprecated"`
+		DeprecationReason string `json:"deprecationReason"`
+	} `json:"enumValues"`
+	PossibleTypes []typeRef `json:"possibleTypes"`
+}
+
+type introspection struct {
+	Data struct {
+		Schema struct {
+			QueryType        *struct{ Name string } `json:"queryType"`
+			MutationType     *struct{ Name string } `json:"mutationType"`
+			SubscriptionType *struct{ Name string } `json:"subscriptionType"`
+			Types            []fullType             `json:"types"`
+		} `json:"__schema"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// builtInScalars are part of every schema and must not be redeclared.
+var builtInScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+
+func main() {
+	network := flag.String("network", os.Getenv("TWINGATE_NETWORK"), "The Twingate network, such as acme for acme.twingate.com.")
+	apiURL := flag.String("api-url", "", "The GraphQL endpoint, instead of the one of --network.")
+	output := flag.String("o", "", "The file to write the schema to, instead of stdout.")
+	flag.Parse()
+
+	if err := run(context.Background(), *network, *apiURL, os.Getenv("TWINGATE_API_KEY"), *output); err != nil {
+		fmt.Fprintln(os.Stderr, "introspect:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, network string, apiURL string, apiKey string, output string) error {
+	if apiURL == "" {
+		if network == "" {
+			return errors.New("--network or TWINGATE_NETWORK is required")
+		}
+		apiURL = fmt.Sprintf("https://%s.twingate.com/api/graphql/", network)
+	}
+	if apiKey == "" {
+		return errors.New("TWINGATE_API_KEY is required")
+	}
+
+	result, err := fetch(ctx, apiURL, apiKey)
+	if err != nil {
+		return err
+	}
+	doc := schemaDocument(result)
+	sdl := &bytes.Buffer{}
+	sdl.WriteString(header)
+	formatter.NewFormatter(sdl, formatter.WithIndent("  ")).FormatSchemaDocument(doc)
+
+	if output == "" {
+		_, err = os.Stdout.Write(sdl.Bytes())
+		return err
+	}
+	return os.WriteFile(output, sdl.Bytes(), 0o644)
+}
+
+func fetch(ctx context.Context, apiURL string, apiKey string) (*introspection, error) {
+	body, err := json.Marshal(map[string]string{"query": introspectionQuery})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-KEY", apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection query returned %s", resp.Status)
+	}
+
+	rv := &introspection{}
+	if err := json.Unmarshal(b, rv); err != nil {
+		return nil, fmt.Errorf("invalid introspection response: %w", err)
+	}
+	if len(rv.Errors) > 0 {
+		return nil, fmt.Errorf("introspection query failed: %s", rv.Errors[0].Message)
+	}
+	if len(rv.Data.Schema.Types) == 0 {
+		return nil, errors.New("introspection response has no types")
+	}
+	return rv, nil
+}
+
+// schemaDocument converts an introspection result to a schema document, with types sorted by name so that updates
+// of the schema diff well.
+func schemaDocument(result *introspection) *ast.SchemaDocument {
+	s := result.Data.Schema
+	doc := &ast.SchemaDocument{}
+
+	schema := &ast.SchemaDefinition{}
+	if s.QueryType != nil && s.QueryType.Name != "Query" {
+		schema.OperationTypes = append(schema.OperationTypes, &ast.OperationTypeDefinition{Operation: ast.Query, Type: s.QueryType.Name})
+	}
+	if s.MutationType != nil && s.MutationType.Name != "Mutation" {
+		schema.OperationTypes = append(schema.OperationTypes, &ast.OperationTypeDefinition{Operation: ast.Mutation, Type: s.MutationType.Name})
+	}
+	if s.SubscriptionType != nil && s.SubscriptionType.Name != "Subscription" {
+		schema.OperationTypes = append(schema.OperationTypes, &ast.OperationTypeDefinition{Operation: ast.Subscription, Type: s.SubscriptionType.Name})
+	}
+	if len(schema.OperationTypes) > 0 {
+		doc.Schema = append(doc.Schema, schema)
+	}
+
+	types := append([]fullType(nil), s.Types...)
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+	for _, t := range types {
+		if strings.HasPrefix(t.Name, "__") || builtInScalars[t.Name] {
+			continue
+		}
+		def := &ast.Definition{Name: t.Name, Description: t.Description}
+		switch t.Kind {
+		case "SCALAR":
+			def.Kind = ast.Scalar
+		case "OBJECT", "INTERFACE":
+			def.Kind = ast.Object
+			if t.Kind == "INTERFACE" {
+				def.Kind = ast.Interface
+			}
+			for _, i := range t.Interfaces {
+				def.Interfaces = append(def.Interfaces, i.Name)
+			}
+			for _, f := range t.Fields {
+				field := &ast.FieldDefinition{Name: f.Name, Description: f.Description, Type: astType(f.Type)}
+				for _, a := range f.Args {
+					field.Arguments = append(field.Arguments, &ast.ArgumentDefinition{
+						Name:         a.Name,
+						Description:  a.Description,
+						Type:         astType(a.Type),
+						DefaultValue: defaultValue(a.DefaultValue),
+					})
+				}
+				if f.IsDeprecated {
+					field.Directives = deprecated(f.DeprecationReason)
+				}
+				def.Fields = append(def.Fields, field)
+			}
+		case "INPUT_OBJECT":
+			def.Kind = ast.InputObject
+			for _, f := range t.InputFields {
+				def.Fields = append(def.Fields, &ast.FieldDefinition{
+					Name:         f.Name,
+					Description:  f.Description,
+					Type:         astType(f.Type),
+					DefaultValue: defaultValue(f.DefaultValue),
+				})
+			}
+		case "ENUM":
+			def.Kind = ast.Enum
+			for _, v := range t.EnumValues {
+				value := &ast.EnumValueDefinition{Name: v.Name, Description: v.Description}
+				if v.IsDeprecated {
+					value.Directives = deprecated(v.DeprecationReason)
+				}
+				def.EnumValues = append(def.EnumValues, value)
+			}
+		case "UNION":
+			def.Kind = ast.Union
+			for _, p := range t.PossibleTypes {
+				def.Types = append(def.Types, p.Name)
+			}
+		default:
+			continue
+		}
+		doc.Definitions = append(doc.Definitions, def)
+	}
+	return doc
+}
+
+func astType(t typeRef) *ast.Type {
+	switch t.Kind {
+	case "NON_NULL":
+		rv := astType(*t.OfType)
+		rv.NonNull = true
+		return rv
+	case "LIST":
+		return &ast.Type{Elem: astType(*t.OfType)}
+	default:
+		return &ast.Type{NamedType: t.Name}
+	}
+}
+
+// defaultValue returns the default value of an argument or input field. Introspection returns it as a GraphQL
+// literal, which is written as it is.
+func defaultValue(v *string) *ast.Value {
+	if v == nil {
+		return nil
+	}
+	return &ast.Value{Kind: ast.EnumValue, Raw: *v}
+}
+
+func deprecated(reason string) ast.DirectiveList {
+	d := &ast.Directive{Name: "deprecated"}
+	if reason != "" {
+		d.Arguments = ast.ArgumentList{{Name: "reason", Value: &ast.Value{Kind: ast.StringValue, Raw: reason}}}
+	}
+	return ast.DirectiveList{d}
+}
@@ -0,0 +1,9 @@
+//go:build tools
+
+// Package tools pins the versions of the code generators run by go generate. It is a separate module so that
+// their dependencies do not end up in the connector's go.mod or vendor directory.
+package tools
+
+import (
+	_ "github.com/Khan/genqlient"
+)